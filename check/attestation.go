@@ -0,0 +1,34 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+// Attestation records the result of a manual check as reviewed by an operator.
+// Until it expires, it replaces the WARN result that manual checks otherwise get.
+type Attestation struct {
+	ID string `yaml:"id" json:"id"`
+	// Benchmark restricts the attestation to one benchmark version, e.g. "cis-1.9".
+	Benchmark string `yaml:"benchmark,omitempty" json:"benchmark,omitempty"`
+	// Status is PASS or FAIL.
+	Status   State  `yaml:"status" json:"status"`
+	Evidence string `yaml:"evidence" json:"evidence"`
+	Reviewer string `yaml:"reviewer" json:"reviewer"`
+	// Date is the day (YYYY-MM-DD) the check was reviewed.
+	Date string `yaml:"date" json:"date"`
+	// Expires is the last day (YYYY-MM-DD) on which the attestation applies.
+	Expires string `yaml:"expires" json:"expires"`
+	// Expired is set on the copy attached to a check when the attestation
+	// matched but could no longer be applied.
+	Expired bool `yaml:"-" json:"expired,omitempty"`
+}
@@ -38,6 +38,8 @@ const (
 	WARN State = "WARN"
 	// INFO informational message
 	INFO State = "INFO"
+	// WAIVED check did not pass but the risk has been accepted in a waiver.
+	WAIVED State = "WAIVED"
 
 	// SKIP for when a check should be skipped.
 	SKIP = "skip"
@@ -71,20 +73,25 @@ type Check struct {
 	AuditEnv          string   `yaml:"audit_env"`
 	AuditConfig       string   `yaml:"audit_config"`
 	Type              string   `json:"type"`
+	Tags              []string `json:"tags,omitempty"`
+	Severity          string   `json:"severity,omitempty"`
 	Tests             *tests   `json:"-"`
 	Set               bool     `json:"-"`
 	Remediation       string   `json:"remediation"`
 	TestInfo          []string `json:"test_info"`
 	State             `json:"status"`
-	ActualValue       string `json:"actual_value"`
-	Scored            bool   `json:"scored"`
-	IsMultiple        bool   `yaml:"use_multiple_values"`
-	ExpectedResult    string `json:"expected_result"`
-	Reason            string `json:"reason,omitempty"`
-	AuditOutput       string `json:"-"`
-	AuditEnvOutput    string `json:"-"`
-	AuditConfigOutput string `json:"-"`
-	DisableEnvTesting bool   `json:"-"`
+	ActualValue       string       `json:"actual_value"`
+	Scored            bool         `json:"scored"`
+	IsMultiple        bool         `yaml:"use_multiple_values"`
+	ExpectedResult    string       `json:"expected_result"`
+	Reason            string       `json:"reason,omitempty"`
+	Known             bool         `yaml:"-" json:"known,omitempty"`
+	AuditOutput       string       `json:"-"`
+	AuditEnvOutput    string       `json:"-"`
+	AuditConfigOutput string       `json:"-"`
+	DisableEnvTesting bool         `json:"-"`
+	Waiver            *Waiver      `yaml:"-" json:"waiver,omitempty"`
+	Attestation       *Attestation `yaml:"-" json:"attestation,omitempty"`
 }
 
 // Runner wraps the basic Run method.
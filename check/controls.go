@@ -41,8 +41,11 @@ const (
 )
 
 type OverallControls struct {
-	Controls []*Controls
-	Totals   Summary
+	SchemaVersion string    `json:"schema_version,omitempty"`
+	Metadata      *Metadata `json:"metadata,omitempty"`
+	Controls      []*Controls
+	Totals        Summary
+	Score         *Score `json:",omitempty"`
 }
 
 // Controls holds all controls to check for master nodes.
@@ -58,14 +61,16 @@ type Controls struct {
 
 // Group is a collection of similar checks.
 type Group struct {
-	ID     string   `yaml:"id" json:"section"`
-	Type   string   `yaml:"type" json:"type"`
-	Pass   int      `json:"pass"`
-	Fail   int      `json:"fail"`
-	Warn   int      `json:"warn"`
-	Info   int      `json:"info"`
-	Text   string   `json:"desc"`
-	Checks []*Check `json:"results"`
+	ID       string   `yaml:"id" json:"section"`
+	Type     string   `yaml:"type" json:"type"`
+	Pass     int      `json:"pass"`
+	Fail     int      `json:"fail"`
+	Warn     int      `json:"warn"`
+	Info     int      `json:"info"`
+	Waived   int      `json:"waived,omitempty"`
+	Text     string   `json:"desc"`
+	Severity string   `json:"severity,omitempty"`
+	Checks   []*Check `json:"results"`
 }
 
 // Summary is a summary of the results of control checks run.
@@ -74,6 +79,8 @@ type Summary struct {
 	Fail int `json:"total_fail"`
 	Warn int `json:"total_warn"`
 	Info int `json:"total_info"`
+	// Waived is omitted when zero so that results without waivers are unchanged.
+	Waived int `json:"total_waived,omitempty"`
 }
 
 // Predicate a predicate on the given Group and Check arguments.
@@ -92,6 +99,15 @@ func NewControls(t NodeType, in []byte, detectedVersion string) (*Controls, erro
 		return nil, fmt.Errorf("non-%s controls file specified", t)
 	}
 	c.DetectedVersion = detectedVersion
+
+	// Checks inherit the severity of their group unless they set their own.
+	for _, g := range c.Groups {
+		for _, check := range g.Checks {
+			if check.Severity == "" {
+				check.Severity = g.Severity
+			}
+		}
+	}
 	return c, nil
 }
 
@@ -99,7 +115,7 @@ func NewControls(t NodeType, in []byte, detectedVersion string) (*Controls, erro
 func (controls *Controls) RunChecks(runner Runner, filter Predicate, skipIDMap map[string]bool) Summary {
 	var g []*Group
 	m := make(map[string]*Group)
-	controls.Summary.Pass, controls.Summary.Fail, controls.Summary.Warn, controls.Info = 0, 0, 0, 0
+	controls.Summary.Pass, controls.Summary.Fail, controls.Summary.Warn, controls.Info, controls.Waived = 0, 0, 0, 0, 0
 
 	for _, group := range controls.Groups {
 		for _, check := range group.Checks {
@@ -123,9 +139,10 @@ func (controls *Controls) RunChecks(runner Runner, filter Predicate, skipIDMap m
 			if v, ok := m[group.ID]; !ok {
 				// Create a group with same info
 				w := &Group{
-					ID:     group.ID,
-					Text:   group.Text,
-					Checks: []*Check{},
+					ID:       group.ID,
+					Text:     group.Text,
+					Severity: group.Severity,
+					Checks:   []*Check{},
 				}
 
 				// Add this check to the new group
@@ -148,19 +165,70 @@ func (controls *Controls) RunChecks(runner Runner, filter Predicate, skipIDMap m
 	return controls.Summary
 }
 
+// UpdateResults returns a copy of the controls, which have been run, in which
+// the checks that were run again in rerun replace those with the same ID. The
+// summaries are counted again; the controls themselves are left unchanged.
+func (controls *Controls) UpdateResults(rerun *Controls) *Controls {
+	results := make(map[string]*Check)
+	for _, group := range rerun.Groups {
+		for _, check := range group.Checks {
+			results[check.ID] = check
+		}
+	}
+
+	updated := *controls
+	updated.Summary = Summary{}
+	updated.Groups = make([]*Group, 0, len(controls.Groups))
+	for _, group := range controls.Groups {
+		g := *group
+		g.Pass, g.Fail, g.Warn, g.Info, g.Waived = 0, 0, 0, 0, 0
+		g.Checks = make([]*Check, 0, len(group.Checks))
+		for _, check := range group.Checks {
+			if result, ok := results[check.ID]; ok {
+				check = result
+			}
+			g.Checks = append(g.Checks, check)
+			summarizeGroup(&g, check.State)
+			summarize(&updated, check.State)
+		}
+		updated.Groups = append(updated.Groups, &g)
+	}
+	return &updated
+}
+
 // JSON encodes the results of last run to JSON.
 func (controls *Controls) JSON() ([]byte, error) {
 	return json.Marshal(controls)
 }
 
-// JUnit encodes the results of last run to JUnit.
-func (controls *Controls) JUnit() ([]byte, error) {
-	suite := reporters.JUnitTestSuite{
+// junitTestSuite is a JUnit test suite with properties, which the ginkgo
+// reporter does not have.
+type junitTestSuite struct {
+	XMLName    xml.Name                  `xml:"testsuite"`
+	Properties *junitProperties          `xml:"properties,omitempty"`
+	TestCases  []reporters.JUnitTestCase `xml:"testcase"`
+	Name       string                    `xml:"name,attr"`
+	Tests      int                       `xml:"tests,attr"`
+	Failures   int                       `xml:"failures,attr"`
+	Errors     int                       `xml:"errors,attr"`
+	Time       float64                   `xml:"time,attr"`
+}
+
+type junitProperties struct {
+	Properties []JUnitProperty `xml:"property"`
+}
+
+// JUnit encodes the results of last run to JUnit, with the given properties on the test suite.
+func (controls *Controls) JUnit(properties ...JUnitProperty) ([]byte, error) {
+	suite := junitTestSuite{
 		Name:      controls.Text,
 		TestCases: []reporters.JUnitTestCase{},
-		Tests:     controls.Summary.Pass + controls.Summary.Fail + controls.Summary.Info + controls.Summary.Warn,
+		Tests:     controls.Summary.Pass + controls.Summary.Fail + controls.Summary.Info + controls.Summary.Warn + controls.Summary.Waived,
 		Failures:  controls.Summary.Fail,
 	}
+	if len(properties) > 0 {
+		suite.Properties = &junitProperties{Properties: properties}
+	}
 	for _, g := range controls.Groups {
 		for _, check := range g.Checks {
 			jsonCheck := ""
@@ -185,6 +253,8 @@ func (controls *Controls) JUnit() ([]byte, error) {
 				// WARN and INFO are two different versions of skipped tests. Either way it would be a false positive/negative to report
 				// it any other way.
 				tc.Skipped = &reporters.JUnitSkipped{}
+			case WAIVED:
+				tc.Skipped = &reporters.JUnitSkipped{Message: check.Reason}
 			case PASS:
 			default:
 				glog.Warningf("Unrecognized state %s", check.State)
@@ -309,6 +379,8 @@ func summarize(controls *Controls, state State) {
 		controls.Summary.Warn++
 	case INFO:
 		controls.Summary.Info++
+	case WAIVED:
+		controls.Summary.Waived++
 	default:
 		glog.Warningf("Unrecognized state %s", state)
 	}
@@ -324,6 +396,8 @@ func summarizeGroup(group *Group, state State) {
 		group.Warn++
 	case INFO:
 		group.Info++
+	case WAIVED:
+		group.Waived++
 	default:
 		glog.Warningf("Unrecognized state %s", state)
 	}
@@ -233,6 +233,58 @@ groups:
 		// and
 		runner.AssertExpectations(t)
 	})
+
+	t.Run("Should count waived checks separately", func(t *testing.T) {
+		// given
+		runner := new(mockRunner)
+		controls, err := NewControls(MASTER, []byte("type: master\ngroups:\n- id: G1\n  checks:\n  - id: G1/C1\n"), "")
+		assert.NoError(t, err)
+		runner.On("Run", controls.Groups[0].Checks[0]).Return(WAIVED)
+		// when
+		controls.RunChecks(runner, func(*Group, *Check) bool { return true }, map[string]bool{})
+		// then
+		assert.Equal(t, 1, controls.Summary.Waived)
+		assert.Equal(t, 0, controls.Summary.Fail)
+		assert.Equal(t, 1, controls.Groups[0].Waived)
+	})
+}
+
+func TestControls_UpdateResults(t *testing.T) {
+	controls := &Controls{
+		Type:    NODE,
+		Summary: Summary{Pass: 2, Fail: 1},
+		Groups: []*Group{
+			{ID: "G1", Pass: 1, Fail: 1, Checks: []*Check{
+				{ID: "G1/C1", State: PASS},
+				{ID: "G1/C2", State: FAIL, ActualValue: "777"},
+			}},
+			{ID: "G2", Pass: 1, Checks: []*Check{
+				{ID: "G2/C1", State: PASS},
+			}},
+		},
+	}
+	rerun := &Controls{
+		Type: NODE,
+		Groups: []*Group{
+			{ID: "G1", Pass: 1, Checks: []*Check{
+				{ID: "G1/C2", State: PASS, ActualValue: "600"},
+			}},
+		},
+	}
+
+	updated := controls.UpdateResults(rerun)
+
+	assert.Equal(t, Summary{Pass: 3}, updated.Summary)
+	assert.Len(t, updated.Groups, 2)
+	assertEqualGroupSummary(t, 2, 0, 0, 0, updated.Groups[0])
+	assert.Same(t, controls.Groups[0].Checks[0], updated.Groups[0].Checks[0])
+	assert.Same(t, rerun.Groups[0].Checks[0], updated.Groups[0].Checks[1])
+	assertEqualGroupSummary(t, 1, 0, 0, 0, updated.Groups[1])
+
+	// The controls the results were updated from are unchanged.
+	assert.Equal(t, Summary{Pass: 2, Fail: 1}, controls.Summary)
+	assertEqualGroupSummary(t, 1, 1, 0, 0, controls.Groups[0])
+	assert.Equal(t, FAIL, controls.Groups[0].Checks[1].State)
 }
 
 func TestControls_JUnitIncludesJSON(t *testing.T) {
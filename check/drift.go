@@ -0,0 +1,146 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+// ChangeKind is how a check differs between two results.
+type ChangeKind string
+
+const (
+	// Regressed check is in a worse state than before, such as PASS to FAIL.
+	Regressed ChangeKind = "regressed"
+	// Improved check is in a better state than before, such as FAIL to PASS.
+	Improved ChangeKind = "improved"
+	// Changed check has a different actual value, or a different state that
+	// is neither better nor worse, such as INFO to WAIVED.
+	Changed ChangeKind = "changed"
+	// Added check is only in the new result.
+	Added ChangeKind = "added"
+	// Removed check is only in the old result.
+	Removed ChangeKind = "removed"
+)
+
+// stateRank orders the states from best to worst, to tell regressions from
+// improvements. WAIVED and INFO rank alike: neither passed, neither failed.
+var stateRank = map[State]int{
+	PASS:   0,
+	WAIVED: 1,
+	INFO:   1,
+	WARN:   2,
+	FAIL:   3,
+}
+
+// CheckChange is a check that differs between two results. Checks are
+// identified by their benchmark, target and ID, since a benchmark can check
+// the same ID in more than one target.
+type CheckChange struct {
+	Kind           ChangeKind `json:"kind"`
+	Benchmark      string     `json:"benchmark"`
+	Target         NodeType   `json:"target"`
+	ID             string     `json:"test_number"`
+	Text           string     `json:"test_desc"`
+	Scored         bool       `json:"scored"`
+	OldState       State      `json:"old_status,omitempty"`
+	NewState       State      `json:"new_status,omitempty"`
+	OldActualValue string     `json:"old_actual_value,omitempty"`
+	NewActualValue string     `json:"new_actual_value,omitempty"`
+}
+
+type resultCheck struct {
+	controls *Controls
+	check    *Check
+}
+
+func resultKey(controls *Controls, c *Check) string {
+	return controls.Version + "/" + string(controls.Type) + "/" + c.ID
+}
+
+func resultChecks(controlsCollection []*Controls) ([]string, map[string]resultCheck) {
+	var keys []string
+	checks := make(map[string]resultCheck)
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				key := resultKey(controls, c)
+				if _, ok := checks[key]; ok {
+					continue
+				}
+				keys = append(keys, key)
+				checks[key] = resultCheck{controls: controls, check: c}
+			}
+		}
+	}
+	return keys, checks
+}
+
+// CompareResults returns the checks that differ between the old and the new
+// results: those of the new results in their order, then those removed.
+func CompareResults(oldResults, newResults []*Controls) []CheckChange {
+	oldKeys, oldChecks := resultChecks(oldResults)
+	newKeys, newChecks := resultChecks(newResults)
+
+	var changes []CheckChange
+	for _, key := range newKeys {
+		n := newChecks[key]
+		change := CheckChange{
+			Benchmark:      n.controls.Version,
+			Target:         n.controls.Type,
+			ID:             n.check.ID,
+			Text:           n.check.Text,
+			Scored:         n.check.Scored,
+			NewState:       n.check.State,
+			NewActualValue: n.check.ActualValue,
+		}
+
+		o, ok := oldChecks[key]
+		if !ok {
+			change.Kind = Added
+			changes = append(changes, change)
+			continue
+		}
+		change.OldState = o.check.State
+		change.OldActualValue = o.check.ActualValue
+
+		oldRank, newRank := stateRank[o.check.State], stateRank[n.check.State]
+		switch {
+		case newRank > oldRank:
+			change.Kind = Regressed
+		case newRank < oldRank:
+			change.Kind = Improved
+		case o.check.State != n.check.State || o.check.ActualValue != n.check.ActualValue:
+			change.Kind = Changed
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	for _, key := range oldKeys {
+		if _, ok := newChecks[key]; ok {
+			continue
+		}
+		o := oldChecks[key]
+		changes = append(changes, CheckChange{
+			Kind:           Removed,
+			Benchmark:      o.controls.Version,
+			Target:         o.controls.Type,
+			ID:             o.check.ID,
+			Text:           o.check.Text,
+			Scored:         o.check.Scored,
+			OldState:       o.check.State,
+			OldActualValue: o.check.ActualValue,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,99 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareResults(t *testing.T) {
+	old := []*Controls{
+		{Version: "cis-1.9", Type: NODE, Groups: []*Group{
+			{ID: "4.1", Checks: []*Check{
+				{ID: "4.1.1", Text: "flipped to fail", State: PASS, ActualValue: "600", Scored: true},
+				{ID: "4.1.2", Text: "flipped to pass", State: FAIL, ActualValue: "777"},
+				{ID: "4.1.3", Text: "value changed", State: PASS, ActualValue: "root:root"},
+				{ID: "4.1.4", Text: "unchanged", State: PASS, ActualValue: "600"},
+				{ID: "4.1.5", Text: "removed", State: WARN},
+				{ID: "4.1.6", Text: "waived", State: INFO},
+			}},
+		}},
+	}
+	new := []*Controls{
+		{Version: "cis-1.9", Type: NODE, Groups: []*Group{
+			{ID: "4.1", Checks: []*Check{
+				{ID: "4.1.1", Text: "flipped to fail", State: FAIL, ActualValue: "644", Scored: true},
+				{ID: "4.1.2", Text: "flipped to pass", State: PASS, ActualValue: "600"},
+				{ID: "4.1.3", Text: "value changed", State: PASS, ActualValue: "root:admin"},
+				{ID: "4.1.4", Text: "unchanged", State: PASS, ActualValue: "600"},
+				{ID: "4.1.6", Text: "waived", State: WAIVED},
+				{ID: "4.1.7", Text: "added", State: PASS},
+			}},
+		}},
+	}
+
+	changes := CompareResults(old, new)
+	assert.Equal(t, []CheckChange{
+		{Kind: Regressed, Benchmark: "cis-1.9", Target: NODE, ID: "4.1.1", Text: "flipped to fail", Scored: true,
+			OldState: PASS, NewState: FAIL, OldActualValue: "600", NewActualValue: "644"},
+		{Kind: Improved, Benchmark: "cis-1.9", Target: NODE, ID: "4.1.2", Text: "flipped to pass",
+			OldState: FAIL, NewState: PASS, OldActualValue: "777", NewActualValue: "600"},
+		{Kind: Changed, Benchmark: "cis-1.9", Target: NODE, ID: "4.1.3", Text: "value changed",
+			OldState: PASS, NewState: PASS, OldActualValue: "root:root", NewActualValue: "root:admin"},
+		{Kind: Changed, Benchmark: "cis-1.9", Target: NODE, ID: "4.1.6", Text: "waived",
+			OldState: INFO, NewState: WAIVED},
+		{Kind: Added, Benchmark: "cis-1.9", Target: NODE, ID: "4.1.7", Text: "added", NewState: PASS},
+		{Kind: Removed, Benchmark: "cis-1.9", Target: NODE, ID: "4.1.5", Text: "removed", OldState: WARN},
+	}, changes)
+
+	assert.Empty(t, CompareResults(new, new))
+}
+
+func TestCompareResultsByBenchmark(t *testing.T) {
+	old := []*Controls{{Version: "cis-1.8", Type: NODE, Groups: []*Group{
+		{ID: "4.1", Checks: []*Check{{ID: "4.1.1", State: PASS}}},
+	}}}
+	new := []*Controls{{Version: "cis-1.9", Type: NODE, Groups: []*Group{
+		{ID: "4.1", Checks: []*Check{{ID: "4.1.1", State: PASS}}},
+	}}}
+
+	changes := CompareResults(old, new)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, Added, changes[0].Kind)
+		assert.Equal(t, "cis-1.9", changes[0].Benchmark)
+		assert.Equal(t, Removed, changes[1].Kind)
+		assert.Equal(t, "cis-1.8", changes[1].Benchmark)
+	}
+}
+
+func TestCompareResultsByTarget(t *testing.T) {
+	results := func(policiesState State) []*Controls {
+		return []*Controls{
+			{Version: "eks-stig-kubernetes-v1r6", Type: CONTROLPLANE, Groups: []*Group{
+				{ID: "1.1", Checks: []*Check{{ID: "V-242381", State: PASS}}},
+			}},
+			{Version: "eks-stig-kubernetes-v1r6", Type: POLICIES, Groups: []*Group{
+				{ID: "2.1", Checks: []*Check{{ID: "V-242381", State: policiesState}}},
+			}},
+		}
+	}
+
+	changes := CompareResults(results(PASS), results(FAIL))
+	assert.Equal(t, []CheckChange{
+		{Kind: Regressed, Benchmark: "eks-stig-kubernetes-v1r6", Target: POLICIES, ID: "V-242381", OldState: PASS, NewState: FAIL},
+	}, changes)
+}
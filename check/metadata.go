@@ -0,0 +1,102 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metadata describes the scan that produced a set of results, so that the
+// results of many nodes can be correlated.
+type Metadata struct {
+	ScanID            string      `json:"scan_id"`
+	Node              string      `json:"node"`
+	KubeBenchVersion  string      `json:"kube_bench_version"`
+	StartTime         time.Time   `json:"start_time"`
+	EndTime           time.Time   `json:"end_time"`
+	DurationSeconds   float64     `json:"duration_seconds"`
+	Benchmark         string      `json:"benchmark"`
+	BenchmarkReason   string      `json:"benchmark_reason,omitempty"`
+	KubernetesVersion string      `json:"kubernetes_version,omitempty"`
+	Platform          string      `json:"platform,omitempty"`
+	Components        []Component `json:"components,omitempty"`
+}
+
+// Component is a component of a target and the binary and files found for it on the node.
+type Component struct {
+	Target     NodeType `json:"target"`
+	Name       string   `json:"name"`
+	Binary     string   `json:"binary,omitempty"`
+	Config     string   `json:"config,omitempty"`
+	Service    string   `json:"service,omitempty"`
+	Kubeconfig string   `json:"kubeconfig,omitempty"`
+	CAFile     string   `json:"cafile,omitempty"`
+	DataDir    string   `json:"datadir,omitempty"`
+}
+
+// JUnitProperty is a property of a JUnit test suite.
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Finish records the end of the scan and its duration.
+func (m *Metadata) Finish(t time.Time) {
+	m.EndTime = t
+	m.DurationSeconds = t.Sub(m.StartTime).Seconds()
+}
+
+// JUnitProperties returns the metadata as JUnit test suite properties.
+func (m *Metadata) JUnitProperties() []JUnitProperty {
+	props := []JUnitProperty{
+		{Name: "scan_id", Value: m.ScanID},
+		{Name: "node", Value: m.Node},
+		{Name: "kube_bench_version", Value: m.KubeBenchVersion},
+		{Name: "start_time", Value: m.StartTime.UTC().Format(time.RFC3339)},
+		{Name: "duration_seconds", Value: fmt.Sprintf("%.3f", m.DurationSeconds)},
+		{Name: "benchmark", Value: m.Benchmark},
+		{Name: "benchmark_reason", Value: m.BenchmarkReason},
+		{Name: "kubernetes_version", Value: m.KubernetesVersion},
+		{Name: "platform", Value: m.Platform},
+	}
+	if !m.EndTime.IsZero() {
+		props = append(props, JUnitProperty{Name: "end_time", Value: m.EndTime.UTC().Format(time.RFC3339)})
+	}
+
+	var components []string
+	for _, c := range m.Components {
+		components = append(components, fmt.Sprintf("%s/%s", c.Target, c.Name))
+		for _, f := range []struct{ kind, path string }{
+			{"binary", c.Binary},
+			{"config", c.Config},
+			{"service", c.Service},
+			{"kubeconfig", c.Kubeconfig},
+			{"cafile", c.CAFile},
+			{"datadir", c.DataDir},
+		} {
+			if f.path != "" {
+				props = append(props, JUnitProperty{Name: fmt.Sprintf("%s.%s.%s", c.Target, c.Name, f.kind), Value: f.path})
+			}
+		}
+	}
+	if len(components) > 0 {
+		sort.Strings(components)
+		props = append(props, JUnitProperty{Name: "components", Value: strings.Join(components, ",")})
+	}
+	return props
+}
@@ -0,0 +1,101 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestMetadataJUnitProperties(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	m := &Metadata{
+		ScanID:           "id-1",
+		Node:             "node-1",
+		KubeBenchVersion: "0.10.0",
+		StartTime:        start,
+		Benchmark:        "cis-1.8",
+		Platform:         "eks 1.27",
+		Components: []Component{
+			{Target: NODE, Name: "kubelet", Binary: "kubelet", Config: "/var/lib/kubelet/config.yaml"},
+			{Target: NODE, Name: "proxy", Binary: "kube-proxy"},
+		},
+	}
+	m.Finish(start.Add(1500 * time.Millisecond))
+	if m.DurationSeconds != 1.5 {
+		t.Errorf("expected a duration of 1.5s, got %v", m.DurationSeconds)
+	}
+
+	props := make(map[string]string)
+	for _, p := range m.JUnitProperties() {
+		props[p.Name] = p.Value
+	}
+	exp := map[string]string{
+		"scan_id":             "id-1",
+		"node":                "node-1",
+		"kube_bench_version":  "0.10.0",
+		"start_time":          "2024-05-01T10:00:00Z",
+		"end_time":            "2024-05-01T10:00:01Z",
+		"duration_seconds":    "1.500",
+		"benchmark":           "cis-1.8",
+		"platform":            "eks 1.27",
+		"node.kubelet.binary": "kubelet",
+		"node.kubelet.config": "/var/lib/kubelet/config.yaml",
+		"node.proxy.binary":   "kube-proxy",
+		"components":          "node/kubelet,node/proxy",
+	}
+	for k, v := range exp {
+		if props[k] != v {
+			t.Errorf("expected property %s=%q, got %q", k, v, props[k])
+		}
+	}
+}
+
+func TestJUnitProperties(t *testing.T) {
+	controls := &Controls{Text: "Worker Node", Groups: []*Group{{Checks: []*Check{{ID: "4.1.1", State: PASS}}}}}
+
+	out, err := controls.JUnit(JUnitProperty{Name: "scan_id", Value: "id-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite struct {
+		Properties []JUnitProperty `xml:"properties>property"`
+		TestCases  []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"testcase"`
+	}
+	if err := xml.Unmarshal(out, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if len(suite.Properties) != 1 || suite.Properties[0].Value != "id-1" {
+		t.Errorf("expected the scan_id property, got %+v", suite.Properties)
+	}
+	if len(suite.TestCases) != 1 {
+		t.Errorf("expected 1 test case, got %d", len(suite.TestCases))
+	}
+
+	out, err = controls.JUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.Properties = nil
+	if err := xml.Unmarshal(out, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if len(suite.Properties) != 0 {
+		t.Errorf("expected no properties, got %+v", suite.Properties)
+	}
+}
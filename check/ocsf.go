@@ -0,0 +1,221 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// OCSFVersion is the version of the Open Cybersecurity Schema Framework the findings follow.
+	OCSFVersion = "1.1.0"
+	// OCSFComplianceFindingClass is the class_uid of an OCSF Compliance Finding.
+	OCSFComplianceFindingClass = 2003
+	// OCSFFindingsCategory is the category_uid of the OCSF Findings category.
+	OCSFFindingsCategory = 2
+	// OCSFActivityCreate is the activity_id of a newly created finding.
+	OCSFActivityCreate = 1
+)
+
+// OCSFScan describes the scan the findings come from.
+type OCSFScan struct {
+	ProductVersion string
+	Node           string
+	Cluster        string
+	Time           time.Time
+}
+
+// OCSFComplianceFinding is an OCSF Compliance Finding event (class 2003).
+type OCSFComplianceFinding struct {
+	ActivityID   int               `json:"activity_id"`
+	ActivityName string            `json:"activity_name"`
+	CategoryUID  int               `json:"category_uid"`
+	CategoryName string            `json:"category_name"`
+	ClassUID     int               `json:"class_uid"`
+	ClassName    string            `json:"class_name"`
+	TypeUID      int               `json:"type_uid"`
+	Time         int64             `json:"time"`
+	SeverityID   int               `json:"severity_id"`
+	Severity     string            `json:"severity"`
+	StatusID     int               `json:"status_id"`
+	Status       string            `json:"status"`
+	Message      string            `json:"message"`
+	Metadata     OCSFMetadata      `json:"metadata"`
+	FindingInfo  OCSFFindingInfo   `json:"finding_info"`
+	Compliance   OCSFCompliance    `json:"compliance"`
+	Remediation  *OCSFRemediation  `json:"remediation,omitempty"`
+	Resources    []OCSFResource    `json:"resources"`
+	Unmapped     map[string]string `json:"unmapped,omitempty"`
+}
+
+// OCSFMetadata describes the product that produced a finding.
+type OCSFMetadata struct {
+	Version string      `json:"version"`
+	Product OCSFProduct `json:"product"`
+}
+
+// OCSFProduct is the product that produced a finding.
+type OCSFProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version,omitempty"`
+	URL        string `json:"url_string"`
+}
+
+// OCSFFindingInfo identifies a finding.
+type OCSFFindingInfo struct {
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	Desc        string   `json:"desc,omitempty"`
+	CreatedTime int64    `json:"created_time"`
+	Types       []string `json:"types,omitempty"`
+}
+
+// OCSFCompliance is the result of a check against a control of a standard.
+type OCSFCompliance struct {
+	Standards    []string `json:"standards"`
+	Control      string   `json:"control"`
+	Requirements []string `json:"requirements,omitempty"`
+	StatusID     int      `json:"status_id"`
+	Status       string   `json:"status"`
+	StatusDetail string   `json:"status_detail,omitempty"`
+}
+
+// OCSFRemediation describes how to fix a finding.
+type OCSFRemediation struct {
+	Desc string `json:"desc"`
+}
+
+// OCSFResource is the node or cluster a finding is about.
+type OCSFResource struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	UID  string `json:"uid,omitempty"`
+}
+
+// ocsfSeverities maps check severities to OCSF severity IDs.
+var ocsfSeverities = map[string]int{
+	"info":     1,
+	"low":      2,
+	"medium":   3,
+	"high":     4,
+	"critical": 5,
+}
+
+var ocsfSeverityNames = map[int]string{
+	0: "Unknown",
+	1: "Informational",
+	2: "Low",
+	3: "Medium",
+	4: "High",
+	5: "Critical",
+}
+
+// OCSF encodes the results of last run to OCSF Compliance Findings, one per check.
+func (controls *Controls) OCSF(scan OCSFScan) []OCSFComplianceFinding {
+	ms := scan.Time.UnixMilli()
+	fs := []OCSFComplianceFinding{}
+	for _, g := range controls.Groups {
+		for _, c := range g.Checks {
+			complianceStatusID, complianceStatus := ocsfComplianceStatus(c.State)
+			statusID, status := 1, "New"
+			if c.State == WAIVED {
+				statusID, status = 3, "Suppressed"
+			}
+
+			resource := ocsfResource(controls.Type, scan)
+			severityID := ocsfSeverities[strings.ToLower(c.Severity)]
+			f := OCSFComplianceFinding{
+				ActivityID:   OCSFActivityCreate,
+				ActivityName: "Create",
+				CategoryUID:  OCSFFindingsCategory,
+				CategoryName: "Findings",
+				ClassUID:     OCSFComplianceFindingClass,
+				ClassName:    "Compliance Finding",
+				TypeUID:      OCSFComplianceFindingClass*100 + OCSFActivityCreate,
+				Time:         ms,
+				SeverityID:   severityID,
+				Severity:     ocsfSeverityNames[severityID],
+				StatusID:     statusID,
+				Status:       status,
+				Message:      fmt.Sprintf("%s %s: %s", c.ID, c.Text, c.State),
+				Metadata: OCSFMetadata{
+					Version: OCSFVersion,
+					Product: OCSFProduct{
+						Name:       "kube-bench",
+						VendorName: "KhulnaSoft",
+						Version:    scan.ProductVersion,
+						URL:        InformationURI,
+					},
+				},
+				FindingInfo: OCSFFindingInfo{
+					UID:         fmt.Sprintf("kube-bench/%s/%s/%s", controls.Version, resource.UID, c.ID),
+					Title:       fmt.Sprintf("%s %s", c.ID, c.Text),
+					Desc:        fmt.Sprintf("%s %s", g.ID, g.Text),
+					CreatedTime: ms,
+					Types:       []string{TYPE},
+				},
+				Compliance: OCSFCompliance{
+					Standards:    []string{controls.Version},
+					Control:      c.ID,
+					Requirements: []string{fmt.Sprintf("%s %s", controls.ID, controls.Text), fmt.Sprintf("%s %s", g.ID, g.Text)},
+					StatusID:     complianceStatusID,
+					Status:       complianceStatus,
+					StatusDetail: c.Reason,
+				},
+				Resources: []OCSFResource{resource},
+				Unmapped: map[string]string{
+					"actual_value":    c.ActualValue,
+					"expected_result": c.ExpectedResult,
+					"scored":          fmt.Sprintf("%t", c.Scored),
+					"target":          string(controls.Type),
+				},
+			}
+			if c.Remediation != "" {
+				f.Remediation = &OCSFRemediation{Desc: c.Remediation}
+			}
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// ocsfComplianceStatus maps a check state to an OCSF compliance status.
+func ocsfComplianceStatus(state State) (int, string) {
+	switch state {
+	case PASS:
+		return 1, "Pass"
+	case WARN:
+		return 2, "Warning"
+	case FAIL:
+		return 3, "Fail"
+	case INFO:
+		return 99, "Info"
+	case WAIVED:
+		return 99, "Waived"
+	default:
+		return 0, "Unknown"
+	}
+}
+
+// ocsfResource returns the cluster for the cluster-wide targets and the node otherwise.
+func ocsfResource(t NodeType, scan OCSFScan) OCSFResource {
+	if t == POLICIES || t == MANAGEDSERVICES {
+		return OCSFResource{Type: "Kubernetes Cluster", Name: scan.Cluster, UID: scan.Cluster}
+	}
+	return OCSFResource{Type: "Kubernetes Node", Name: scan.Node, UID: scan.Node}
+}
@@ -0,0 +1,74 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControls_OCSF(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	scan := OCSFScan{ProductVersion: "v0.10.0", Node: "worker-1", Cluster: "prod", Time: now}
+
+	controls := &Controls{
+		ID:      "4",
+		Version: "cis-1.9",
+		Text:    "Worker Node Security Configuration",
+		Type:    NODE,
+		Groups: []*Group{{
+			ID:   "4.1",
+			Text: "Worker Node Configuration Files",
+			Checks: []*Check{
+				{ID: "4.1.1", Text: "Ensure kubelet service file permissions", State: FAIL, Severity: "high", Remediation: "chmod 600", ActualValue: "777", ExpectedResult: "600"},
+				{ID: "4.1.2", Text: "Ensure kubelet service file ownership", State: WAIVED, Reason: "Waived (was FAIL): managed"},
+				{ID: "4.1.3", Text: "Ensure proxy kubeconfig permissions", State: PASS},
+			},
+		}},
+	}
+
+	fs := controls.OCSF(scan)
+	assert.Len(t, fs, 3)
+
+	f := fs[0]
+	assert.Equal(t, 2003, f.ClassUID)
+	assert.Equal(t, 200301, f.TypeUID)
+	assert.Equal(t, now.UnixMilli(), f.Time)
+	assert.Equal(t, 4, f.SeverityID)
+	assert.Equal(t, "High", f.Severity)
+	assert.Equal(t, "v0.10.0", f.Metadata.Product.Version)
+	assert.Equal(t, "kube-bench/cis-1.9/worker-1/4.1.1", f.FindingInfo.UID)
+	assert.Equal(t, OCSFCompliance{
+		Standards:    []string{"cis-1.9"},
+		Control:      "4.1.1",
+		Requirements: []string{"4 Worker Node Security Configuration", "4.1 Worker Node Configuration Files"},
+		StatusID:     3,
+		Status:       "Fail",
+	}, f.Compliance)
+	assert.Equal(t, "chmod 600", f.Remediation.Desc)
+	assert.Equal(t, []OCSFResource{{Type: "Kubernetes Node", Name: "worker-1", UID: "worker-1"}}, f.Resources)
+	assert.Equal(t, "777", f.Unmapped["actual_value"])
+
+	assert.Equal(t, "Suppressed", fs[1].Status)
+	assert.Equal(t, "Waived", fs[1].Compliance.Status)
+	assert.Equal(t, "Waived (was FAIL): managed", fs[1].Compliance.StatusDetail)
+	assert.Equal(t, 1, fs[2].Compliance.StatusID)
+	assert.Nil(t, fs[2].Remediation)
+
+	controls.Type = POLICIES
+	assert.Equal(t, []OCSFResource{{Type: "Kubernetes Cluster", Name: "prod", UID: "prod"}}, controls.OCSF(scan)[0].Resources)
+}
@@ -0,0 +1,282 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// OSCALVersion is the version of OSCAL the assessment results follow.
+	OSCALVersion = "1.1.2"
+	// OSCALNamespace is the namespace of the kube-bench properties in OSCAL documents.
+	OSCALNamespace = "https://github.com/khulnasoft-lab/kube-bench/ns/oscal"
+)
+
+var oscalTokenUnsafe = regexp.MustCompile(`[^\p{L}\p{N}._\-]+`)
+
+type oscalDocument struct {
+	AssessmentResults oscalAssessmentResults `json:"assessment-results"`
+}
+
+type oscalAssessmentResults struct {
+	UUID       string           `json:"uuid"`
+	Metadata   oscalMetadata    `json:"metadata"`
+	ImportAP   oscalImport      `json:"import-ap"`
+	Results    []oscalResult    `json:"results"`
+	BackMatter *oscalBackMatter `json:"back-matter,omitempty"`
+}
+
+type oscalMetadata struct {
+	Title        string      `json:"title"`
+	LastModified string      `json:"last-modified"`
+	Version      string      `json:"version"`
+	OSCALVersion string      `json:"oscal-version"`
+	Props        []oscalProp `json:"props,omitempty"`
+}
+
+type oscalImport struct {
+	Href string `json:"href"`
+}
+
+type oscalProp struct {
+	Name  string `json:"name"`
+	NS    string `json:"ns,omitempty"`
+	Value string `json:"value"`
+}
+
+type oscalResult struct {
+	UUID             string                `json:"uuid"`
+	Title            string                `json:"title"`
+	Description      string                `json:"description"`
+	Start            string                `json:"start"`
+	End              string                `json:"end,omitempty"`
+	Props            []oscalProp           `json:"props,omitempty"`
+	ReviewedControls oscalReviewedControls `json:"reviewed-controls"`
+	Observations     []oscalObservation    `json:"observations,omitempty"`
+	Findings         []oscalFinding        `json:"findings,omitempty"`
+}
+
+type oscalReviewedControls struct {
+	ControlSelections []oscalControlSelection `json:"control-selections"`
+}
+
+type oscalControlSelection struct {
+	IncludeAll      *struct{}               `json:"include-all,omitempty"`
+	IncludeControls []oscalControlReference `json:"include-controls,omitempty"`
+}
+
+type oscalControlReference struct {
+	ControlID string `json:"control-id"`
+}
+
+type oscalObservation struct {
+	UUID             string          `json:"uuid"`
+	Title            string          `json:"title"`
+	Description      string          `json:"description"`
+	Props            []oscalProp     `json:"props,omitempty"`
+	Methods          []string        `json:"methods"`
+	RelevantEvidence []oscalEvidence `json:"relevant-evidence,omitempty"`
+	Collected        string          `json:"collected"`
+	Remarks          string          `json:"remarks,omitempty"`
+}
+
+type oscalEvidence struct {
+	Description string `json:"description"`
+	Remarks     string `json:"remarks,omitempty"`
+}
+
+type oscalFinding struct {
+	UUID                string                    `json:"uuid"`
+	Title               string                    `json:"title"`
+	Description         string                    `json:"description"`
+	Target              oscalFindingTarget        `json:"target"`
+	RelatedObservations []oscalRelatedObservation `json:"related-observations"`
+	Remarks             string                    `json:"remarks,omitempty"`
+}
+
+type oscalFindingTarget struct {
+	Type     string             `json:"type"`
+	TargetID string             `json:"target-id"`
+	Status   oscalFindingStatus `json:"status"`
+}
+
+type oscalFindingStatus struct {
+	State string `json:"state"`
+}
+
+type oscalRelatedObservation struct {
+	ObservationUUID string `json:"observation-uuid"`
+}
+
+type oscalBackMatter struct {
+	Resources []oscalResource `json:"resources"`
+}
+
+type oscalResource struct {
+	UUID        string      `json:"uuid"`
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Props       []oscalProp `json:"props,omitempty"`
+}
+
+// OSCAL encodes the results of a scan to OSCAL Assessment Results. Each check
+// is an observation, with the output of its audit commands as evidence, and
+// each failed check is a finding. The benchmark is the referenced profile.
+func OSCAL(controlsCollection []*Controls, node, toolVersion string, t time.Time) ([]byte, error) {
+	ts := t.UTC().Format(time.RFC3339)
+
+	var benchmarks []string
+	for _, controls := range controlsCollection {
+		if controls.Version != "" && !contains(benchmarks, controls.Version) {
+			benchmarks = append(benchmarks, controls.Version)
+		}
+	}
+
+	profile := oscalResource{
+		UUID:        uuid.NewString(),
+		Title:       strings.Join(benchmarks, ", "),
+		Description: "The benchmark the checks were run from.",
+		Props:       []oscalProp{{Name: "type", Value: "policy"}},
+	}
+	for _, b := range benchmarks {
+		profile.Props = append(profile.Props, oscalProp{Name: "benchmark", NS: OSCALNamespace, Value: b})
+	}
+
+	result := oscalResult{
+		UUID:        uuid.NewString(),
+		Title:       fmt.Sprintf("kube-bench scan of %s", node),
+		Description: fmt.Sprintf("Results of running the %s benchmark on %s.", strings.Join(benchmarks, ", "), node),
+		Start:       ts,
+		End:         ts,
+		Props:       []oscalProp{{Name: "node", NS: OSCALNamespace, Value: node}},
+	}
+
+	selection := oscalControlSelection{}
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				selection.IncludeControls = append(selection.IncludeControls, oscalControlReference{ControlID: oscalToken(c.ID)})
+
+				obs := newOSCALObservation(controls, g, c, ts)
+				result.Observations = append(result.Observations, obs)
+
+				if c.State != FAIL {
+					continue
+				}
+				result.Findings = append(result.Findings, oscalFinding{
+					UUID:        uuid.NewString(),
+					Title:       fmt.Sprintf("%s %s", c.ID, c.Text),
+					Description: fmt.Sprintf("Check %s of %s failed on %s.", c.ID, controls.Version, node),
+					Target: oscalFindingTarget{
+						Type:     "objective-id",
+						TargetID: oscalToken(c.ID),
+						Status:   oscalFindingStatus{State: "not-satisfied"},
+					},
+					RelatedObservations: []oscalRelatedObservation{{ObservationUUID: obs.UUID}},
+					Remarks:             c.Remediation,
+				})
+			}
+		}
+	}
+	if len(selection.IncludeControls) == 0 {
+		selection = oscalControlSelection{IncludeAll: &struct{}{}}
+	}
+	result.ReviewedControls = oscalReviewedControls{ControlSelections: []oscalControlSelection{selection}}
+
+	doc := oscalDocument{AssessmentResults: oscalAssessmentResults{
+		UUID: uuid.NewString(),
+		Metadata: oscalMetadata{
+			Title:        "kube-bench assessment results",
+			LastModified: ts,
+			Version:      toolVersion,
+			OSCALVersion: OSCALVersion,
+		},
+		ImportAP:   oscalImport{Href: "#" + profile.UUID},
+		Results:    []oscalResult{result},
+		BackMatter: &oscalBackMatter{Resources: []oscalResource{profile}},
+	}}
+	if doc.AssessmentResults.Metadata.Version == "" {
+		doc.AssessmentResults.Metadata.Version = "unknown"
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OSCAL assessment results: %v", err)
+	}
+	return out, nil
+}
+
+// oscalToken returns a check ID as an OSCAL token, which must start with a
+// letter or an underscore: 1.1.1 becomes _1.1.1, and V-242381 is unchanged.
+func oscalToken(checkID string) string {
+	token := oscalTokenUnsafe.ReplaceAllString(checkID, "-")
+	if r, _ := utf8.DecodeRuneInString(token); token == "" || !(unicode.IsLetter(r) || r == '_') {
+		token = "_" + token
+	}
+	return token
+}
+
+func newOSCALObservation(controls *Controls, g *Group, c *Check, ts string) oscalObservation {
+	method := "TEST"
+	if c.Type == MANUAL {
+		method = "EXAMINE"
+	}
+
+	obs := oscalObservation{
+		UUID:        uuid.NewString(),
+		Title:       fmt.Sprintf("%s %s", c.ID, c.Text),
+		Description: fmt.Sprintf("%s %s: %s", g.ID, g.Text, c.State),
+		Props: []oscalProp{
+			{Name: "check-id", NS: OSCALNamespace, Value: c.ID},
+			{Name: "target", NS: OSCALNamespace, Value: string(controls.Type)},
+			{Name: "state", NS: OSCALNamespace, Value: string(c.State)},
+			{Name: "scored", NS: OSCALNamespace, Value: fmt.Sprintf("%t", c.Scored)},
+		},
+		Methods:   []string{method},
+		Collected: ts,
+		Remarks:   c.Reason,
+	}
+
+	for _, e := range []struct{ command, output string }{
+		{c.Audit, c.AuditOutput},
+		{c.AuditConfig, c.AuditConfigOutput},
+		{c.AuditEnv, c.AuditEnvOutput},
+	} {
+		if e.command == "" {
+			continue
+		}
+		obs.RelevantEvidence = append(obs.RelevantEvidence, oscalEvidence{
+			Description: fmt.Sprintf("Output of %s", e.command),
+			Remarks:     e.output,
+		})
+	}
+	if c.ActualValue != "" {
+		description := "Actual value"
+		if c.ExpectedResult != "" {
+			description = fmt.Sprintf("Actual value, expected %s", c.ExpectedResult)
+		}
+		obs.RelevantEvidence = append(obs.RelevantEvidence, oscalEvidence{Description: description, Remarks: c.ActualValue})
+	}
+	return obs
+}
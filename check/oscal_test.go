@@ -0,0 +1,101 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// oscalTokenPattern is the pattern of the token type in the OSCAL schemas.
+var oscalTokenPattern = regexp.MustCompile(`^(\p{L}|_)(\p{L}|\p{N}|[.\-_])*$`)
+
+func TestOSCAL(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	controls := []*Controls{{
+		Version: "cis-1.9",
+		Type:    MASTER,
+		Groups: []*Group{{
+			ID:   "1.1",
+			Text: "Control Plane Node Configuration Files",
+			Checks: []*Check{
+				{
+					ID:             "1.1.1",
+					Text:           "Ensure API server pod specification file permissions",
+					Audit:          "stat -c permissions=%a /etc/kubernetes/manifests/kube-apiserver.yaml",
+					AuditOutput:    "permissions=777",
+					Scored:         true,
+					State:          FAIL,
+					ActualValue:    "permissions=777",
+					ExpectedResult: "permissions has permissions 777, expected 600 or more restrictive",
+					Remediation:    "chmod 600 /etc/kubernetes/manifests/kube-apiserver.yaml",
+				},
+				{ID: "1.1.2", Text: "Manual", Type: MANUAL, State: WARN, Reason: "Test marked as a manual test"},
+			},
+		}},
+	}}
+
+	out, err := OSCAL(controls, "master-1", "v0.10.0", now)
+	assert.NoError(t, err)
+
+	var doc oscalDocument
+	assert.NoError(t, json.Unmarshal(out, &doc))
+	ar := doc.AssessmentResults
+	assert.Equal(t, OSCALVersion, ar.Metadata.OSCALVersion)
+	assert.Equal(t, "v0.10.0", ar.Metadata.Version)
+	assert.Equal(t, "#"+ar.BackMatter.Resources[0].UUID, ar.ImportAP.Href)
+	assert.Equal(t, "cis-1.9", ar.BackMatter.Resources[0].Title)
+	assert.Len(t, ar.Results, 1)
+
+	r := ar.Results[0]
+	assert.Equal(t, "2024-06-01T12:00:00Z", r.Start)
+	assert.Equal(t, []oscalControlReference{{ControlID: "_1.1.1"}, {ControlID: "_1.1.2"}}, r.ReviewedControls.ControlSelections[0].IncludeControls)
+	assert.Len(t, r.Observations, 2)
+
+	obs := r.Observations[0]
+	assert.Equal(t, []string{"TEST"}, obs.Methods)
+	assert.Contains(t, obs.Props, oscalProp{Name: "state", NS: OSCALNamespace, Value: "FAIL"})
+	assert.Equal(t, oscalEvidence{Description: "Output of stat -c permissions=%a /etc/kubernetes/manifests/kube-apiserver.yaml", Remarks: "permissions=777"}, obs.RelevantEvidence[0])
+	assert.Len(t, obs.RelevantEvidence, 2)
+	assert.Equal(t, []string{"EXAMINE"}, r.Observations[1].Methods)
+
+	assert.Len(t, r.Findings, 1)
+	f := r.Findings[0]
+	assert.Equal(t, "_1.1.1", f.Target.TargetID)
+	for _, ref := range r.ReviewedControls.ControlSelections[0].IncludeControls {
+		assert.Regexp(t, oscalTokenPattern, ref.ControlID)
+	}
+	assert.Regexp(t, oscalTokenPattern, f.Target.TargetID)
+	assert.Equal(t, "not-satisfied", f.Target.Status.State)
+	assert.Equal(t, obs.UUID, f.RelatedObservations[0].ObservationUUID)
+}
+
+func TestOSCALToken(t *testing.T) {
+	cases := map[string]string{
+		"1.1.1":    "_1.1.1",
+		"V-242381": "V-242381",
+		"_x":       "_x",
+		"5.1 (a)":  "_5.1-a-",
+		"":         "_",
+	}
+	for id, expected := range cases {
+		assert.Equal(t, expected, oscalToken(id), id)
+		assert.Regexp(t, oscalTokenPattern, oscalToken(id), id)
+	}
+}
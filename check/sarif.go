@@ -0,0 +1,285 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SARIFVersion is the version of the SARIF format written by SARIF.
+	SARIFVersion = "2.1.0"
+	// SARIFSchema is the JSON schema of the SARIF format written by SARIF.
+	SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+	// InformationURI is the home page of kube-bench.
+	InformationURI = "https://github.com/khulnasoft-lab/kube-bench"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool              `json:"tool"`
+	Results    []sarifResult          `json:"results"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	Help                 *sarifMessage          `json:"help,omitempty"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	BaselineState       string                 `json:"baselineState,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// securitySeverities maps check severities to the numeric scores that code
+// scanning dashboards use to rank SARIF rules.
+var securitySeverities = map[string]string{
+	"critical": "9.5",
+	"high":     "8.0",
+	"medium":   "5.5",
+	"low":      "3.0",
+	"info":     "0.0",
+}
+
+// auditPath matches absolute file paths in an audit command.
+var auditPath = regexp.MustCompile(`(?:^|[\s'"=:])(/(?:[\w.\-]+/)*[\w.\-]+)`)
+
+// SARIF encodes the results of a scan to a SARIF 2.1.0 log with a single run.
+// Each check is a rule, and each FAIL or WARN check is a result.
+func SARIF(controlsCollection []*Controls, toolVersion string) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "kube-bench",
+			Version:        toolVersion,
+			InformationURI: InformationURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	var benchmarks []string
+	ruleIndex := make(map[string]int)
+	for _, controls := range controlsCollection {
+		if controls.Version != "" && !contains(benchmarks, controls.Version) {
+			benchmarks = append(benchmarks, controls.Version)
+		}
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				idx, ok := ruleIndex[c.ID]
+				if !ok {
+					idx = len(run.Tool.Driver.Rules)
+					ruleIndex[c.ID] = idx
+					run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(g, c))
+				}
+
+				if c.State != FAIL && c.State != WARN {
+					continue
+				}
+				run.Results = append(run.Results, newSARIFResult(controls, g, c, idx, run.Tool.Driver.Rules[idx].DefaultConfiguration.Level))
+			}
+		}
+	}
+	if len(benchmarks) > 0 {
+		run.Properties = map[string]interface{}{"benchmarks": benchmarks}
+	}
+
+	out, err := json.MarshalIndent(sarifLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SARIF report: %v", err)
+	}
+	return out, nil
+}
+
+func newSARIFRule(g *Group, c *Check) sarifRule {
+	r := sarifRule{
+		ID:                   c.ID,
+		ShortDescription:     sarifMessage{Text: c.Text},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(c)},
+		Properties: map[string]interface{}{
+			"group":  fmt.Sprintf("%s %s", g.ID, g.Text),
+			"scored": c.Scored,
+		},
+	}
+	if c.Remediation != "" {
+		r.Help = &sarifMessage{Text: c.Remediation}
+	}
+	if len(c.Tags) > 0 {
+		r.Properties["tags"] = c.Tags
+	}
+	if c.Severity != "" {
+		r.Properties["severity"] = c.Severity
+		if s, ok := securitySeverities[strings.ToLower(c.Severity)]; ok {
+			r.Properties["security-severity"] = s
+		}
+	}
+	return r
+}
+
+func newSARIFResult(controls *Controls, g *Group, c *Check, ruleIndex int, ruleLevel string) sarifResult {
+	level := ruleLevel
+	if c.State == WARN {
+		level = "warning"
+	}
+
+	msg := fmt.Sprintf("%s %s", c.ID, c.Text)
+	if c.ExpectedResult != "" || c.ActualValue != "" {
+		msg = fmt.Sprintf("%s\nExpected: %s\nActual: %s", msg, c.ExpectedResult, c.ActualValue)
+	}
+	if c.Reason != "" {
+		msg = fmt.Sprintf("%s\nReason: %s", msg, c.Reason)
+	}
+
+	loc := sarifLocation{
+		LogicalLocations: []sarifLogicalLocation{{
+			Name:               string(controls.Type),
+			FullyQualifiedName: fmt.Sprintf("%s/%s", controls.Type, g.ID),
+			Kind:               "module",
+		}},
+	}
+	if path := auditFile(c); path != "" {
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "file://" + path},
+		}
+	}
+
+	// Failures known from a baseline are unchanged since it.
+	var baselineState string
+	if c.Known {
+		baselineState = "unchanged"
+	}
+
+	return sarifResult{
+		RuleID:    c.ID,
+		RuleIndex: ruleIndex,
+		Level:     level,
+		Message:   sarifMessage{Text: msg},
+		Locations: []sarifLocation{loc},
+		PartialFingerprints: map[string]string{
+			"kubeBenchCheck/v1": fmt.Sprintf("%s/%s/%s", controls.Version, controls.Type, c.ID),
+		},
+		BaselineState: baselineState,
+		Properties: map[string]interface{}{
+			"status":         c.State,
+			"actualValue":    c.ActualValue,
+			"expectedResult": c.ExpectedResult,
+		},
+	}
+}
+
+// sarifLevel returns the SARIF level of a failing check from its severity,
+// falling back to whether the check is scored.
+func sarifLevel(c *Check) string {
+	switch strings.ToLower(c.Severity) {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	case "low", "info":
+		return "note"
+	}
+	if c.Scored {
+		return "error"
+	}
+	return "warning"
+}
+
+// auditFile returns the first file the audit of a check reads, preferring the
+// audit_config command. Executables and /proc are not considered.
+func auditFile(c *Check) string {
+	for _, audit := range []string{c.AuditConfig, c.Audit} {
+		for _, m := range auditPath.FindAllStringSubmatch(audit, -1) {
+			path := m[1]
+			if isExecutablePath(path) {
+				continue
+			}
+			return path
+		}
+	}
+	return ""
+}
+
+func isExecutablePath(path string) bool {
+	for _, prefix := range []string{"/bin/", "/sbin/", "/usr/bin/", "/usr/sbin/", "/usr/local/bin/", "/proc/", "/dev/"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSARIF(t *testing.T) {
+	controls := []*Controls{{
+		ID:      "1",
+		Version: "cis-1.9",
+		Type:    MASTER,
+		Groups: []*Group{{
+			ID:   "1.1",
+			Text: "Control Plane Node Configuration Files",
+			Checks: []*Check{
+				{
+					ID:             "1.1.1",
+					Text:           "Ensure that the API server pod specification file permissions are set to 600",
+					Audit:          "/bin/sh -c 'if test -e /etc/kubernetes/manifests/kube-apiserver.yaml; then stat -c permissions=%a /etc/kubernetes/manifests/kube-apiserver.yaml; fi'",
+					Remediation:    "chmod 600 /etc/kubernetes/manifests/kube-apiserver.yaml",
+					Scored:         true,
+					Severity:       "high",
+					Tags:           []string{"permissions"},
+					State:          FAIL,
+					ActualValue:    "permissions=644",
+					ExpectedResult: "permissions has permissions 644, expected 600 or more restrictive",
+				},
+				{ID: "1.1.2", Text: "Ensure ownership", Audit: "/bin/ps -ef | grep kube-apiserver", Scored: true, State: PASS},
+				{ID: "1.1.3", Text: "Manual check", Type: MANUAL, State: WARN, Reason: "Test marked as a manual test"},
+			},
+		}},
+	}}
+
+	out, err := SARIF(controls, "v0.10.0")
+	assert.NoError(t, err)
+
+	var log sarifLog
+	assert.NoError(t, json.Unmarshal(out, &log))
+	assert.Equal(t, SARIFVersion, log.Version)
+	assert.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, "kube-bench", run.Tool.Driver.Name)
+	assert.Equal(t, "v0.10.0", run.Tool.Driver.Version)
+	assert.Len(t, run.Tool.Driver.Rules, 3)
+
+	rule := run.Tool.Driver.Rules[0]
+	assert.Equal(t, "1.1.1", rule.ID)
+	assert.Equal(t, "error", rule.DefaultConfiguration.Level)
+	assert.Equal(t, "chmod 600 /etc/kubernetes/manifests/kube-apiserver.yaml", rule.Help.Text)
+	assert.Equal(t, "8.0", rule.Properties["security-severity"])
+	assert.Equal(t, []interface{}{"permissions"}, rule.Properties["tags"])
+	assert.Nil(t, run.Tool.Driver.Rules[2].Help)
+
+	assert.Len(t, run.Results, 2)
+	failed := run.Results[0]
+	assert.Equal(t, "1.1.1", failed.RuleID)
+	assert.Equal(t, 0, failed.RuleIndex)
+	assert.Equal(t, "error", failed.Level)
+	assert.Contains(t, failed.Message.Text, "Actual: permissions=644")
+	assert.Equal(t, "file:///etc/kubernetes/manifests/kube-apiserver.yaml", failed.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "master/1.1", failed.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, "cis-1.9/master/1.1.1", failed.PartialFingerprints["kubeBenchCheck/v1"])
+	assert.Empty(t, failed.BaselineState)
+
+	warned := run.Results[1]
+	assert.Equal(t, "1.1.3", warned.RuleID)
+	assert.Equal(t, 2, warned.RuleIndex)
+	assert.Equal(t, "warning", warned.Level)
+	assert.Nil(t, warned.Locations[0].PhysicalLocation)
+
+	controls[0].Groups[0].Checks[0].Known = true
+	out, err = SARIF(controls, "v0.10.0")
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(out, &log))
+	assert.Equal(t, "unchanged", log.Runs[0].Results[0].BaselineState, "failures known from the baseline are unchanged")
+}
+
+func TestAuditFile(t *testing.T) {
+	cases := []struct {
+		check    Check
+		expected string
+	}{
+		{check: Check{Audit: "stat -c %a /etc/kubernetes/manifests/etcd.yaml"}, expected: "/etc/kubernetes/manifests/etcd.yaml"},
+		{check: Check{Audit: "/bin/ps -fC kubelet", AuditConfig: "/bin/cat /var/lib/kubelet/config.yaml"}, expected: "/var/lib/kubelet/config.yaml"},
+		{check: Check{Audit: "/bin/ps -ef | grep kube-apiserver | grep -v grep"}, expected: ""},
+		{check: Check{Audit: "ps -ef | grep etcd"}, expected: ""},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, auditFile(&c.check), c.check.Audit)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import _ "embed"
+
+// SchemaVersion is the version of the JSON result schema. The minor version
+// is bumped when fields are added and the major version when fields are
+// removed, renamed or change type.
+const SchemaVersion = "1.1.0"
+
+// ResultSchema is the JSON Schema of the JSON results of this SchemaVersion.
+//
+//go:embed result.schema.json
+var ResultSchema []byte
@@ -0,0 +1,126 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/internal/schematest"
+)
+
+func TestResultSchemaVersion(t *testing.T) {
+	var schema struct {
+		ID string `json:"$id"`
+	}
+	if err := json.Unmarshal(ResultSchema, &schema); err != nil {
+		t.Fatalf("failed to parse the schema: %v", err)
+	}
+	if !strings.HasSuffix(schema.ID, "/"+SchemaVersion) {
+		t.Errorf("expected the schema $id %q to end with the schema version %s", schema.ID, SchemaVersion)
+	}
+}
+
+// TestResultSchemaAllFields guards the schema against struct changes: every
+// field that can be written must be in the schema, with the right type.
+func TestResultSchemaAllFields(t *testing.T) {
+	score := Score{Overall: 50, Targets: map[string]float64{"node": 50}, Groups: map[string]float64{"4.1": 50}}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	results := OverallControls{
+		SchemaVersion: SchemaVersion,
+		Metadata: &Metadata{
+			ScanID:            "id-1",
+			Node:              "node-1",
+			KubeBenchVersion:  "0.10.0",
+			StartTime:         start,
+			EndTime:           start.Add(time.Second),
+			DurationSeconds:   1,
+			Benchmark:         "cis-1.8",
+			BenchmarkReason:   "set with --benchmark",
+			KubernetesVersion: "1.27",
+			Platform:          "eks 1.27",
+			Components:        []Component{{Target: NODE, Name: "kubelet", Binary: "kubelet", Config: "/etc/kubelet.conf", Service: "s", Kubeconfig: "k", CAFile: "c", DataDir: "d"}},
+		},
+		Controls: []*Controls{{
+			ID:              "4",
+			Version:         "cis-1.8",
+			DetectedVersion: "1.27",
+			Text:            "Worker Node Security Configuration",
+			Type:            NODE,
+			Groups: []*Group{{
+				ID:       "4.1",
+				Type:     "",
+				Pass:     1,
+				Fail:     1,
+				Waived:   1,
+				Text:     "Worker Node Configuration Files",
+				Severity: "high",
+				Checks: []*Check{
+					{ID: "4.1.1", Text: "t", Audit: "a", AuditEnv: "e", AuditConfig: "c", Tags: []string{"cis"}, Severity: "high", TestInfo: []string{"i"}, State: PASS, Scored: true, IsMultiple: true, Reason: "r"},
+					{ID: "4.1.2", Text: "t", State: WAIVED, Waiver: &Waiver{ID: "4.1.2", Scope: WaiverScope{Nodes: []string{"n"}, Benchmarks: []string{"b"}, Targets: []string{"node"}}, Justification: "j", Owner: "o", Ticket: "T-1", Expires: "2030-01-01", Expired: true}},
+					{ID: "4.1.3", Text: "t", State: FAIL, Known: true, Attestation: &Attestation{ID: "4.1.3", Benchmark: "cis-1.8", Status: FAIL, Evidence: "e", Reviewer: "r", Date: "2024-01-01", Expires: "2030-01-01", Expired: true}},
+				},
+			}},
+			Summary: Summary{Pass: 1, Fail: 1, Waived: 1},
+		}},
+		Totals: Summary{Pass: 1, Fail: 1, Waived: 1},
+		Score:  &score,
+	}
+
+	out, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schematest.Validate(ResultSchema, out); err != nil {
+		t.Errorf("expected the results to match the schema: %v", err)
+	}
+
+	out, err = json.Marshal(results.Controls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schematest.Validate(ResultSchema, out); err != nil {
+		t.Errorf("expected the results without totals to match the schema: %v", err)
+	}
+}
+
+func TestResultSchemaErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		result string
+		expect string
+	}{
+		{name: "not JSON", result: `{`, expect: "failed to parse results"},
+		{name: "wrong top level type", result: `"results"`, expect: "matches 0 of the oneOf schemas"},
+		{name: "missing totals", result: `{"Controls": []}`, expect: `missing required property "Totals"`},
+		{name: "unknown field", result: `{"Controls": [], "Totals": {"total_pass": 0, "total_fail": 0, "total_warn": 0, "total_info": 0}, "extra": 1}`, expect: "$.extra: is not allowed"},
+		{name: "wrong type", result: `{"Controls": [], "Totals": {"total_pass": "1", "total_fail": 0, "total_warn": 0, "total_info": 0}}`, expect: "$.Totals.total_pass: is string, want integer"},
+		{name: "not an integer", result: `{"Controls": [], "Totals": {"total_pass": 1.5, "total_fail": 0, "total_warn": 0, "total_info": 0}}`, expect: "want integer"},
+		{name: "unknown state", result: `[{"id": "4", "version": "cis-1.8", "text": "", "node_type": "node", "total_pass": 0, "total_fail": 0, "total_warn": 0, "total_info": 0, "tests": [{"section": "4.1", "pass": 0, "fail": 0, "warn": 0, "info": 0, "desc": "", "results": [{"test_number": "4.1.1", "test_desc": "", "audit": "", "type": "", "remediation": "", "test_info": null, "status": "OK", "actual_value": "", "scored": true, "expected_result": ""}]}]}]`, expect: "$[0].tests[0].results[0].status: OK is not one of"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := schematest.Validate(ResultSchema, []byte(c.result))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), c.expect) {
+				t.Errorf("expected %q in the error, got %v", c.expect, err)
+			}
+		})
+	}
+}
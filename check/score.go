@@ -0,0 +1,96 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import "strings"
+
+// DefaultWeight is the weight of a check whose severity has no configured weight.
+const DefaultWeight = 1.0
+
+// Score holds compliance scores, as percentages, for a set of controls.
+// A score is the weighted share of evaluated checks (PASS or FAIL) that passed;
+// WARN, INFO and WAIVED checks do not count. A score with nothing evaluated is 100.
+type Score struct {
+	Overall float64            `json:"overall"`
+	Targets map[string]float64 `json:"targets"`
+	Groups  map[string]float64 `json:"groups"`
+}
+
+type scoreTally struct {
+	passed, evaluated float64
+}
+
+func (t *scoreTally) add(c *Check, weights map[string]float64) {
+	if c.State != PASS && c.State != FAIL {
+		return
+	}
+	w := CheckWeight(c, weights)
+	t.evaluated += w
+	if c.State == PASS {
+		t.passed += w
+	}
+}
+
+func (t scoreTally) percent() float64 {
+	if t.evaluated == 0 {
+		return 100
+	}
+	return 100 * t.passed / t.evaluated
+}
+
+// CheckWeight returns the weight of a check given weights keyed by lower-case severity.
+func CheckWeight(c *Check, weights map[string]float64) float64 {
+	if w, ok := weights[strings.ToLower(c.Severity)]; ok && c.Severity != "" {
+		return w
+	}
+	return DefaultWeight
+}
+
+// ComputeScore computes the overall, per target and per group compliance scores.
+func ComputeScore(controlsCollection []*Controls, weights map[string]float64) Score {
+	var overall scoreTally
+	targets := make(map[string]*scoreTally)
+	groups := make(map[string]*scoreTally)
+
+	for _, controls := range controlsCollection {
+		target := string(controls.Type)
+		if targets[target] == nil {
+			targets[target] = &scoreTally{}
+		}
+		for _, g := range controls.Groups {
+			if groups[g.ID] == nil {
+				groups[g.ID] = &scoreTally{}
+			}
+			for _, c := range g.Checks {
+				overall.add(c, weights)
+				targets[target].add(c, weights)
+				groups[g.ID].add(c, weights)
+			}
+		}
+	}
+
+	score := Score{
+		Overall: overall.percent(),
+		Targets: make(map[string]float64, len(targets)),
+		Groups:  make(map[string]float64, len(groups)),
+	}
+	for k, v := range targets {
+		score.Targets[k] = v.percent()
+	}
+	for k, v := range groups {
+		score.Groups[k] = v.percent()
+	}
+	return score
+}
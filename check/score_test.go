@@ -0,0 +1,80 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeScore(t *testing.T) {
+	controlsCollection := []*Controls{
+		{
+			Type: MASTER,
+			Groups: []*Group{
+				{ID: "1.1", Checks: []*Check{
+					{ID: "1.1.1", State: PASS, Severity: "high"},
+					{ID: "1.1.2", State: FAIL, Severity: "low"},
+					{ID: "1.1.3", State: WARN, Severity: "high"},
+				}},
+				{ID: "1.2", Checks: []*Check{
+					{ID: "1.2.1", State: INFO},
+					{ID: "1.2.2", State: WAIVED},
+				}},
+			},
+		},
+		{
+			Type: NODE,
+			Groups: []*Group{
+				{ID: "4.1", Checks: []*Check{
+					{ID: "4.1.1", State: FAIL},
+				}},
+			},
+		},
+	}
+
+	t.Run("unweighted", func(t *testing.T) {
+		score := ComputeScore(controlsCollection, nil)
+		assert.InDelta(t, 100.0/3, score.Overall, 0.001)
+		assert.Equal(t, 50.0, score.Targets["master"])
+		assert.Equal(t, 0.0, score.Targets["node"])
+		assert.Equal(t, 50.0, score.Groups["1.1"])
+		assert.Equal(t, 100.0, score.Groups["1.2"])
+	})
+
+	t.Run("weighted by severity", func(t *testing.T) {
+		score := ComputeScore(controlsCollection, map[string]float64{"high": 3, "low": 1})
+		assert.Equal(t, 60.0, score.Overall)
+		assert.Equal(t, 75.0, score.Targets["master"])
+	})
+}
+
+func TestNewControlsInheritsGroupSeverity(t *testing.T) {
+	in := []byte(`
+type: node
+groups:
+- id: 3.1
+  severity: high
+  checks:
+  - id: V-1
+  - id: V-2
+    severity: low
+`)
+	controls, err := NewControls(NODE, in, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "high", controls.Groups[0].Checks[0].Severity)
+	assert.Equal(t, "low", controls.Groups[0].Checks[1].Severity)
+}
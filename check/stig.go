@@ -0,0 +1,398 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// STIG Viewer vulnerability statuses, as written in .ckl checklists.
+const (
+	STIGNotAFinding   = "NotAFinding"
+	STIGOpen          = "Open"
+	STIGNotReviewed   = "Not_Reviewed"
+	STIGNotApplicable = "Not_Applicable"
+)
+
+// stigNamespace is the namespace of the name-based UUIDs in .cklb checklists,
+// so that the same benchmark and check always get the same UUID.
+var stigNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte(InformationURI))
+
+var stigRelease = regexp.MustCompile(`v(\d+)r(\d+)`)
+
+type cklChecklist struct {
+	XMLName xml.Name  `xml:"CHECKLIST"`
+	Asset   cklAsset  `xml:"ASSET"`
+	STIGs   []cklSTIG `xml:"STIGS>iSTIG"`
+}
+
+type cklAsset struct {
+	Role          string `xml:"ROLE"`
+	AssetType     string `xml:"ASSET_TYPE"`
+	HostName      string `xml:"HOST_NAME"`
+	HostIP        string `xml:"HOST_IP"`
+	HostMAC       string `xml:"HOST_MAC"`
+	HostFQDN      string `xml:"HOST_FQDN"`
+	TargetComment string `xml:"TARGET_COMMENT"`
+	TechArea      string `xml:"TECH_AREA"`
+	TargetKey     string `xml:"TARGET_KEY"`
+	WebOrDatabase bool   `xml:"WEB_OR_DATABASE"`
+	WebDBSite     string `xml:"WEB_DB_SITE"`
+	WebDBInstance string `xml:"WEB_DB_INSTANCE"`
+}
+
+type cklSTIG struct {
+	Info  []cklSIData `xml:"STIG_INFO>SI_DATA"`
+	Vulns []cklVuln   `xml:"VULN"`
+}
+
+type cklSIData struct {
+	Name string `xml:"SID_NAME"`
+	Data string `xml:"SID_DATA"`
+}
+
+type cklVuln struct {
+	Data                  []cklSTIGData `xml:"STIG_DATA"`
+	Status                string        `xml:"STATUS"`
+	FindingDetails        string        `xml:"FINDING_DETAILS"`
+	Comments              string        `xml:"COMMENTS"`
+	SeverityOverride      string        `xml:"SEVERITY_OVERRIDE"`
+	SeverityJustification string        `xml:"SEVERITY_JUSTIFICATION"`
+}
+
+type cklSTIGData struct {
+	Attribute string `xml:"VULN_ATTRIBUTE"`
+	Data      string `xml:"ATTRIBUTE_DATA"`
+}
+
+type cklbChecklist struct {
+	Title      string         `json:"title"`
+	ID         string         `json:"id"`
+	Active     bool           `json:"active"`
+	Mode       int            `json:"mode"`
+	HasPath    bool           `json:"has_path"`
+	TargetData cklbTargetData `json:"target_data"`
+	STIGs      []cklbSTIG     `json:"stigs"`
+}
+
+type cklbTargetData struct {
+	TargetType     string `json:"target_type"`
+	HostName       string `json:"host_name"`
+	IPAddress      string `json:"ip_address"`
+	MACAddress     string `json:"mac_address"`
+	FQDN           string `json:"fqdn"`
+	Comments       string `json:"comments"`
+	Role           string `json:"role"`
+	IsWebDatabase  bool   `json:"is_web_database"`
+	TechnologyArea string `json:"technology_area"`
+	WebDBSite      string `json:"web_db_site"`
+	WebDBInstance  string `json:"web_db_instance"`
+}
+
+type cklbSTIG struct {
+	STIGName            string     `json:"stig_name"`
+	DisplayName         string     `json:"display_name"`
+	STIGID              string     `json:"stig_id"`
+	Version             string     `json:"version"`
+	ReleaseInfo         string     `json:"release_info"`
+	UUID                string     `json:"uuid"`
+	ReferenceIdentifier string     `json:"reference_identifier"`
+	Size                int        `json:"size"`
+	Rules               []cklbRule `json:"rules"`
+}
+
+type cklbRule struct {
+	UUID           string                 `json:"uuid"`
+	STIGUUID       string                 `json:"stig_uuid"`
+	GroupID        string                 `json:"group_id"`
+	GroupTitle     string                 `json:"group_title"`
+	RuleTitle      string                 `json:"rule_title"`
+	Severity       string                 `json:"severity"`
+	FixText        string                 `json:"fix_text"`
+	Status         string                 `json:"status"`
+	FindingDetails string                 `json:"finding_details"`
+	Comments       string                 `json:"comments"`
+	Overrides      map[string]interface{} `json:"overrides"`
+}
+
+// stigRule is a STIG ID of a benchmark with its checklist status. The same
+// STIG ID can be checked in more than one target, and a checklist has one
+// vulnerability per ID, so its checks are merged: the worst status wins and
+// the findings of each target are combined.
+type stigRule struct {
+	group    *Group
+	check    *Check
+	status   string
+	findings []stigFinding
+}
+
+// stigFinding is the check of a STIG ID in one target.
+type stigFinding struct {
+	target NodeType
+	check  *Check
+}
+
+// stigBenchmark is the checks of one STIG benchmark, in the order they were run.
+type stigBenchmark struct {
+	name  string
+	rules []*stigRule
+}
+
+// stigStatusRank orders the STIG statuses from the best to the worst, to pick
+// the status of a STIG ID checked in more than one target.
+var stigStatusRank = map[string]int{
+	STIGNotApplicable: 0,
+	STIGNotAFinding:   1,
+	STIGNotReviewed:   2,
+	STIGOpen:          3,
+}
+
+// IsSTIGBenchmark reports whether a benchmark is a DISA STIG, whose checks are keyed by STIG IDs.
+func IsSTIGBenchmark(benchmark string) bool {
+	return strings.Contains(strings.ToLower(benchmark), "stig")
+}
+
+// STIGStatus maps the state of a check to a STIG Viewer vulnerability status.
+// Waived checks stay Open, with the waiver in the comments, since accepting a
+// risk does not close a finding.
+func STIGStatus(c *Check) string {
+	switch XCCDFResult(c) {
+	case "pass":
+		return STIGNotAFinding
+	case "fail":
+		return STIGOpen
+	case "informational":
+		if c.State == WAIVED {
+			return STIGOpen
+		}
+		return STIGNotApplicable
+	default:
+		return STIGNotReviewed
+	}
+}
+
+func stigBenchmarks(controlsCollection []*Controls) ([]stigBenchmark, error) {
+	var benchmarks []stigBenchmark
+	index := make(map[string]int)
+	rules := make(map[string]*stigRule)
+	for _, controls := range controlsCollection {
+		if !IsSTIGBenchmark(controls.Version) {
+			return nil, fmt.Errorf("STIG checklists can only be written for STIG benchmarks, not %q", controls.Version)
+		}
+		i, ok := index[controls.Version]
+		if !ok {
+			i = len(benchmarks)
+			index[controls.Version] = i
+			benchmarks = append(benchmarks, stigBenchmark{name: controls.Version})
+		}
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				finding := stigFinding{target: controls.Type, check: c}
+				status := STIGStatus(c)
+				r, ok := rules[controls.Version+"/"+c.ID]
+				if !ok {
+					r = &stigRule{group: g, check: c, status: status}
+					rules[controls.Version+"/"+c.ID] = r
+					benchmarks[i].rules = append(benchmarks[i].rules, r)
+				} else if stigStatusRank[status] > stigStatusRank[r.status] {
+					r.status = status
+				}
+				r.findings = append(r.findings, finding)
+			}
+		}
+	}
+	return benchmarks, nil
+}
+
+// stigVersion returns the version and release of a benchmark named like eks-stig-kubernetes-v1r6.
+func stigVersion(benchmark string) (string, string) {
+	m := stigRelease.FindStringSubmatch(benchmark)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], fmt.Sprintf("Release: %s", m[2])
+}
+
+func stigSeverity(severity string) string {
+	switch s := strings.ToLower(severity); s {
+	case "critical":
+		return "high"
+	case "high", "medium", "low":
+		return s
+	default:
+		return "medium"
+	}
+}
+
+func stigFindingDetails(c *Check) string {
+	if c.ActualValue == "" {
+		return ""
+	}
+	if c.ExpectedResult == "" {
+		return c.ActualValue
+	}
+	return fmt.Sprintf("%s\n\nExpected: %s", c.ActualValue, c.ExpectedResult)
+}
+
+// details returns the finding details of a rule, with the target each comes
+// from when the rule was checked in more than one.
+func (r *stigRule) details() string {
+	return r.combine(stigFindingDetails)
+}
+
+// comments returns the reasons given for the checks of a rule, with the
+// target each comes from when the rule was checked in more than one.
+func (r *stigRule) comments() string {
+	return r.combine(func(c *Check) string { return c.Reason })
+}
+
+func (r *stigRule) combine(text func(*Check) string) string {
+	if len(r.findings) == 1 {
+		return text(r.findings[0].check)
+	}
+	var parts []string
+	for _, f := range r.findings {
+		if t := text(f.check); t != "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", f.target, t))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// STIGChecklist encodes the results of a STIG scan to a STIG Viewer .ckl checklist.
+func STIGChecklist(controlsCollection []*Controls, node string) ([]byte, error) {
+	benchmarks, err := stigBenchmarks(controlsCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	ckl := cklChecklist{
+		Asset: cklAsset{Role: "None", AssetType: "Computing", HostName: node, TechArea: "Kubernetes"},
+	}
+	for _, b := range benchmarks {
+		version, release := stigVersion(b.name)
+		stig := cklSTIG{Info: []cklSIData{
+			{Name: "version", Data: version},
+			{Name: "stigid", Data: b.name},
+			{Name: "releaseinfo", Data: release},
+			{Name: "title", Data: b.name},
+		}}
+		for _, r := range b.rules {
+			stig.Vulns = append(stig.Vulns, cklVuln{
+				Data: []cklSTIGData{
+					{Attribute: "Vuln_Num", Data: r.check.ID},
+					{Attribute: "Severity", Data: stigSeverity(r.check.Severity)},
+					{Attribute: "Group_Title", Data: r.group.Text},
+					{Attribute: "Rule_Title", Data: r.check.Text},
+					{Attribute: "Fix_Text", Data: r.check.Remediation},
+					{Attribute: "STIGRef", Data: b.name},
+				},
+				Status:         r.status,
+				FindingDetails: r.details(),
+				Comments:       r.comments(),
+			})
+		}
+		ckl.STIGs = append(ckl.STIGs, stig)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(ckl); err != nil {
+		return nil, fmt.Errorf("failed to generate STIG checklist: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// STIGChecklistJSON encodes the results of a STIG scan to a STIG Viewer 3 .cklb checklist.
+func STIGChecklistJSON(controlsCollection []*Controls, node string) ([]byte, error) {
+	benchmarks, err := stigBenchmarks(controlsCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	cklb := cklbChecklist{
+		Title:   fmt.Sprintf("kube-bench %s", node),
+		ID:      uuid.NewSHA1(stigNamespace, []byte("checklist/"+node)).String(),
+		Mode:    1,
+		HasPath: true,
+		TargetData: cklbTargetData{
+			TargetType:     "Computing",
+			HostName:       node,
+			Role:           "None",
+			TechnologyArea: "Kubernetes",
+		},
+		STIGs: []cklbSTIG{},
+	}
+	for _, b := range benchmarks {
+		version, release := stigVersion(b.name)
+		stigUUID := uuid.NewSHA1(stigNamespace, []byte(b.name)).String()
+		stig := cklbSTIG{
+			STIGName:            b.name,
+			DisplayName:         b.name,
+			STIGID:              b.name,
+			Version:             version,
+			ReleaseInfo:         release,
+			UUID:                stigUUID,
+			ReferenceIdentifier: b.name,
+			Size:                len(b.rules),
+			Rules:               []cklbRule{},
+		}
+		for _, r := range b.rules {
+			stig.Rules = append(stig.Rules, cklbRule{
+				UUID:           uuid.NewSHA1(stigNamespace, []byte(b.name+"/"+r.check.ID)).String(),
+				STIGUUID:       stigUUID,
+				GroupID:        r.check.ID,
+				GroupTitle:     r.group.Text,
+				RuleTitle:      r.check.Text,
+				Severity:       stigSeverity(r.check.Severity),
+				FixText:        r.check.Remediation,
+				Status:         cklbStatus(r.status),
+				FindingDetails: r.details(),
+				Comments:       r.comments(),
+				Overrides:      map[string]interface{}{},
+			})
+		}
+		cklb.STIGs = append(cklb.STIGs, stig)
+	}
+
+	out, err := json.MarshalIndent(cklb, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate STIG checklist: %v", err)
+	}
+	return out, nil
+}
+
+// cklbStatus converts a .ckl status to its .cklb spelling, e.g. NotAFinding to not_a_finding.
+func cklbStatus(status string) string {
+	switch status {
+	case STIGNotAFinding:
+		return "not_a_finding"
+	case STIGOpen:
+		return "open"
+	case STIGNotApplicable:
+		return "not_applicable"
+	default:
+		return "not_reviewed"
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stigControls() []*Controls {
+	return []*Controls{{
+		Version: "eks-stig-kubernetes-v1r6",
+		Type:    NODE,
+		Groups: []*Group{{
+			ID:   "3.1",
+			Text: "DISA Category Code I",
+			Checks: []*Check{
+				{ID: "V-242387", Text: "The Kubernetes Kubelet must have the read-only port flag disabled", Severity: "high", Scored: true, State: FAIL, ActualValue: "readOnlyPort=10255", ExpectedResult: "'readOnlyPort' is equal to '0'", Remediation: "Set readOnlyPort to 0"},
+				{ID: "V-242391", Text: "Anonymous authentication disabled", Severity: "high", Scored: true, State: PASS},
+				{ID: "V-242392", Text: "Authorization mode", Type: MANUAL, State: WARN, Reason: "Test marked as a manual test"},
+				{ID: "V-242393", Text: "Skipped", Type: SKIP, State: INFO, Reason: "Test marked as skip"},
+			},
+		}},
+	}}
+}
+
+func TestSTIGChecklist(t *testing.T) {
+	out, err := STIGChecklist(stigControls(), "worker-1")
+	assert.NoError(t, err)
+
+	var ckl cklChecklist
+	assert.NoError(t, xml.Unmarshal(out, &ckl))
+	assert.Equal(t, "worker-1", ckl.Asset.HostName)
+	assert.Len(t, ckl.STIGs, 1)
+	assert.Contains(t, ckl.STIGs[0].Info, cklSIData{Name: "version", Data: "1"})
+	assert.Contains(t, ckl.STIGs[0].Info, cklSIData{Name: "releaseinfo", Data: "Release: 6"})
+
+	vulns := ckl.STIGs[0].Vulns
+	assert.Len(t, vulns, 4)
+	assert.Equal(t, cklSTIGData{Attribute: "Vuln_Num", Data: "V-242387"}, vulns[0].Data[0])
+	assert.Equal(t, STIGOpen, vulns[0].Status)
+	assert.Equal(t, "readOnlyPort=10255\n\nExpected: 'readOnlyPort' is equal to '0'", vulns[0].FindingDetails)
+	assert.Equal(t, STIGNotAFinding, vulns[1].Status)
+	assert.Equal(t, STIGNotReviewed, vulns[2].Status)
+	assert.Equal(t, "Test marked as a manual test", vulns[2].Comments)
+	assert.Equal(t, STIGNotApplicable, vulns[3].Status)
+}
+
+func TestSTIGChecklistJSON(t *testing.T) {
+	out, err := STIGChecklistJSON(stigControls(), "worker-1")
+	assert.NoError(t, err)
+
+	var cklb cklbChecklist
+	assert.NoError(t, json.Unmarshal(out, &cklb))
+	assert.Len(t, cklb.STIGs, 1)
+	assert.Equal(t, 4, cklb.STIGs[0].Size)
+
+	rules := cklb.STIGs[0].Rules
+	assert.Equal(t, "V-242387", rules[0].GroupID)
+	assert.Equal(t, "open", rules[0].Status)
+	assert.Equal(t, "high", rules[0].Severity)
+	assert.Equal(t, cklb.STIGs[0].UUID, rules[0].STIGUUID)
+	assert.Equal(t, "not_a_finding", rules[1].Status)
+	assert.Equal(t, "not_reviewed", rules[2].Status)
+	assert.Equal(t, "not_applicable", rules[3].Status)
+
+	again, err := STIGChecklistJSON(stigControls(), "worker-1")
+	assert.NoError(t, err)
+	assert.Equal(t, out, again, "UUIDs should be stable across runs")
+}
+
+func TestSTIGChecklistMergesTargets(t *testing.T) {
+	controls := []*Controls{
+		{Version: "eks-stig-kubernetes-v1r6", Type: CONTROLPLANE, Groups: []*Group{{ID: "1.1", Text: "Control plane", Checks: []*Check{
+			{ID: "V-242381", Text: "Unique service accounts", State: PASS, ActualValue: "controllers use service accounts"},
+			{ID: "V-242402", Text: "Audit log path", State: PASS},
+		}}}},
+		{Version: "eks-stig-kubernetes-v1r6", Type: POLICIES, Groups: []*Group{{ID: "2.1", Text: "Policies", Checks: []*Check{
+			{ID: "V-242381", Text: "Unique service accounts", Type: MANUAL, State: FAIL, ActualValue: "default service account is used", Reason: "Review the service accounts"},
+		}}}},
+	}
+
+	out, err := STIGChecklist(controls, "worker-1")
+	assert.NoError(t, err)
+	var ckl cklChecklist
+	assert.NoError(t, xml.Unmarshal(out, &ckl))
+	vulns := ckl.STIGs[0].Vulns
+	assert.Len(t, vulns, 2)
+	assert.Equal(t, cklSTIGData{Attribute: "Vuln_Num", Data: "V-242381"}, vulns[0].Data[0])
+	assert.Equal(t, STIGOpen, vulns[0].Status)
+	assert.Equal(t, "controlplane: controllers use service accounts\n\npolicies: default service account is used", vulns[0].FindingDetails)
+	assert.Equal(t, "policies: Review the service accounts", vulns[0].Comments)
+	assert.Equal(t, STIGNotAFinding, vulns[1].Status)
+
+	out, err = STIGChecklistJSON(controls, "worker-1")
+	assert.NoError(t, err)
+	var cklb cklbChecklist
+	assert.NoError(t, json.Unmarshal(out, &cklb))
+	rules := cklb.STIGs[0].Rules
+	assert.Len(t, rules, 2)
+	assert.Equal(t, 2, cklb.STIGs[0].Size)
+	assert.Equal(t, "open", rules[0].Status)
+	assert.NotEqual(t, rules[0].UUID, rules[1].UUID)
+}
+
+func TestSTIGChecklistRequiresSTIGBenchmark(t *testing.T) {
+	_, err := STIGChecklist([]*Controls{{Version: "cis-1.9"}}, "worker-1")
+	assert.Error(t, err)
+	_, err = STIGChecklistJSON([]*Controls{{Version: "cis-1.9"}}, "worker-1")
+	assert.Error(t, err)
+}
+
+func TestSTIGStatusWaived(t *testing.T) {
+	assert.Equal(t, STIGOpen, STIGStatus(&Check{State: WAIVED}))
+}
@@ -0,0 +1,42 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+// Waiver is an accepted-risk exception for a check. A check matching an active
+// waiver is still audited, but reported as WAIVED instead of FAIL or WARN.
+type Waiver struct {
+	// ID is a check ID, or a glob such as "4.2.*".
+	ID            string      `yaml:"id" json:"id"`
+	Scope         WaiverScope `yaml:"scope" json:"scope"`
+	Justification string      `yaml:"justification" json:"justification"`
+	Owner         string      `yaml:"owner" json:"owner"`
+	Ticket        string      `yaml:"ticket" json:"ticket,omitempty"`
+	// Expires is the last day (YYYY-MM-DD) on which the waiver applies.
+	Expires string `yaml:"expires" json:"expires"`
+	// Expired is set on the copy attached to a check when the waiver matched
+	// but could no longer be applied.
+	Expired bool `yaml:"-" json:"expired,omitempty"`
+	// State is set on the copy attached to a waived check to the state the
+	// check had before it was waived.
+	State State `yaml:"-" json:"state,omitempty"`
+}
+
+// WaiverScope restricts a waiver to some nodes, benchmarks or targets. Empty
+// lists match everything; entries may be globs.
+type WaiverScope struct {
+	Nodes      []string `yaml:"nodes" json:"nodes,omitempty"`
+	Benchmarks []string `yaml:"benchmarks" json:"benchmarks,omitempty"`
+	Targets    []string `yaml:"targets" json:"targets,omitempty"`
+}
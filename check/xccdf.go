@@ -0,0 +1,314 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// XCCDFNamespace is the namespace of XCCDF 1.2.
+	XCCDFNamespace = "http://checklists.nist.gov/xccdf/1.2"
+	// XCCDFIDPrefix is the reverse-DNS prefix of the XCCDF IDs written by kube-bench.
+	XCCDFIDPrefix = "xccdf_com.khulnasoft.kube-bench"
+
+	stigIdentSystem = "http://cyber.mil/legacy"
+	cisIdentSystem  = "https://www.cisecurity.org/benchmark/kubernetes"
+)
+
+type xccdfTestResult struct {
+	XMLName     xml.Name          `xml:"http://checklists.nist.gov/xccdf/1.2 TestResult"`
+	ID          string            `xml:"id,attr"`
+	StartTime   string            `xml:"start-time,attr"`
+	EndTime     string            `xml:"end-time,attr"`
+	TestSystem  string            `xml:"test-system,attr,omitempty"`
+	Version     string            `xml:"version,attr"`
+	Benchmark   xccdfBenchmarkRef `xml:"benchmark"`
+	Title       string            `xml:"title"`
+	Target      string            `xml:"target"`
+	TargetFacts []xccdfFact       `xml:"target-facts>fact,omitempty"`
+	RuleResults []xccdfRuleResult `xml:"rule-result"`
+	Score       xccdfScore        `xml:"score"`
+}
+
+type xccdfBenchmarkRef struct {
+	ID   string `xml:"id,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type xccdfFact struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xccdfRuleResult struct {
+	IDRef    string         `xml:"idref,attr"`
+	Role     string         `xml:"role,attr"`
+	Time     string         `xml:"time,attr"`
+	Severity string         `xml:"severity,attr"`
+	Result   string         `xml:"result"`
+	Override *xccdfOverride `xml:"override,omitempty"`
+	Idents   []xccdfIdent   `xml:"ident"`
+	Messages []xccdfMessage `xml:"message,omitempty"`
+}
+
+type xccdfOverride struct {
+	Time      string `xml:"time,attr"`
+	Authority string `xml:"authority,attr"`
+	OldResult string `xml:"old-result"`
+	NewResult string `xml:"new-result"`
+	Remark    string `xml:"remark"`
+}
+
+type xccdfIdent struct {
+	System string `xml:"system,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type xccdfMessage struct {
+	Severity string `xml:"severity,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type xccdfScore struct {
+	System  string `xml:"system,attr"`
+	Maximum string `xml:"maximum,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type arfReportCollection struct {
+	XMLName        xml.Name          `xml:"arf:asset-report-collection"`
+	ARFNamespace   string            `xml:"xmlns:arf,attr"`
+	CoreNamespace  string            `xml:"xmlns:core,attr"`
+	AINamespace    string            `xml:"xmlns:ai,attr"`
+	VocabNamespace string            `xml:"xmlns:arfvocab,attr"`
+	Relationships  []arfRelationship `xml:"core:relationships>core:relationship"`
+	Assets         []arfAsset        `xml:"arf:assets>arf:asset"`
+	Reports        []arfReport       `xml:"arf:reports>arf:report"`
+}
+
+type arfRelationship struct {
+	Type    string `xml:"type,attr"`
+	Subject string `xml:"subject,attr"`
+	Ref     string `xml:"core:ref"`
+}
+
+type arfAsset struct {
+	ID       string `xml:"id,attr"`
+	Hostname string `xml:"ai:computing-device>ai:hostname"`
+}
+
+type arfReport struct {
+	ID         string          `xml:"id,attr"`
+	TestResult xccdfTestResult `xml:"arf:content>TestResult"`
+}
+
+var xccdfIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9.\-]+`)
+
+// XCCDFRuleID returns the XCCDF rule ID of a check in a benchmark.
+func XCCDFRuleID(benchmark, checkID string) string {
+	return fmt.Sprintf("%s_rule_%s_%s", XCCDFIDPrefix, xccdfIDUnsafe.ReplaceAllString(benchmark, "-"), xccdfIDUnsafe.ReplaceAllString(checkID, "-"))
+}
+
+// XCCDF encodes the results of a scan to an XCCDF 1.2 TestResult, or with arf
+// to an Asset Reporting Format collection with a TestResult per benchmark.
+func XCCDF(controlsCollection []*Controls, node, toolVersion string, t time.Time, arf bool) ([]byte, error) {
+	var benchmarks []string
+	byBenchmark := make(map[string][]*Controls)
+	for _, controls := range controlsCollection {
+		if _, ok := byBenchmark[controls.Version]; !ok {
+			benchmarks = append(benchmarks, controls.Version)
+		}
+		byBenchmark[controls.Version] = append(byBenchmark[controls.Version], controls)
+	}
+
+	var doc interface{}
+	if arf {
+		collection := arfReportCollection{
+			ARFNamespace:   "http://scap.nist.gov/schema/asset-reporting-format/1.1",
+			CoreNamespace:  "http://scap.nist.gov/schema/reporting-core/1.1",
+			AINamespace:    "http://scap.nist.gov/schema/asset-identification/1.1",
+			VocabNamespace: "http://scap.nist.gov/specifications/arf/vocabulary/relationships/1.0#",
+			Assets:         []arfAsset{{ID: "asset0", Hostname: node}},
+		}
+		for i, benchmark := range benchmarks {
+			id := fmt.Sprintf("xccdf%d", i+1)
+			collection.Relationships = append(collection.Relationships, arfRelationship{Type: "arfvocab:isAbout", Subject: id, Ref: "asset0"})
+			collection.Reports = append(collection.Reports, arfReport{ID: id, TestResult: newXCCDFTestResult(benchmark, byBenchmark[benchmark], node, toolVersion, t)})
+		}
+		doc = collection
+	} else {
+		if len(benchmarks) != 1 {
+			return nil, fmt.Errorf("an XCCDF TestResult holds the results of one benchmark, found %d; use ARF instead", len(benchmarks))
+		}
+		doc = newXCCDFTestResult(benchmarks[0], controlsCollection, node, toolVersion, t)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&b)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to generate XCCDF report: %v", err)
+	}
+	return b.Bytes(), nil
+}
+
+func newXCCDFTestResult(benchmark string, controlsCollection []*Controls, node, toolVersion string, t time.Time) xccdfTestResult {
+	ts := t.UTC().Format(time.RFC3339)
+	benchmarkID := fmt.Sprintf("%s_benchmark_%s", XCCDFIDPrefix, xccdfIDUnsafe.ReplaceAllString(benchmark, "-"))
+	tr := xccdfTestResult{
+		ID:         fmt.Sprintf("%s_testresult_%s", XCCDFIDPrefix, xccdfIDUnsafe.ReplaceAllString(benchmark, "-")),
+		StartTime:  ts,
+		EndTime:    ts,
+		TestSystem: fmt.Sprintf("cpe:/a:khulnasoft:kube-bench:%s", toolVersion),
+		Version:    benchmark,
+		Benchmark:  xccdfBenchmarkRef{ID: benchmarkID, Href: "#" + benchmarkID},
+		Title:      fmt.Sprintf("kube-bench results for %s", benchmark),
+		Target:     node,
+		TargetFacts: []xccdfFact{
+			{Name: "urn:xccdf:fact:asset:identifier:host_name", Type: "string", Value: node},
+		},
+	}
+
+	var passed, evaluated int
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				rr := newXCCDFRuleResult(benchmark, c, ts)
+				switch rr.Result {
+				case "pass":
+					passed++
+					evaluated++
+				case "fail":
+					evaluated++
+				}
+				tr.RuleResults = append(tr.RuleResults, rr)
+			}
+		}
+	}
+
+	tr.Score = xccdfScore{
+		System:  "urn:xccdf:scoring:flat-unweighted",
+		Maximum: fmt.Sprintf("%d", evaluated),
+		Value:   fmt.Sprintf("%d", passed),
+	}
+	return tr
+}
+
+func newXCCDFRuleResult(benchmark string, c *Check, ts string) xccdfRuleResult {
+	rr := xccdfRuleResult{
+		IDRef:    XCCDFRuleID(benchmark, c.ID),
+		Role:     "full",
+		Time:     ts,
+		Severity: xccdfSeverity(c.Severity),
+		Result:   XCCDFResult(c),
+	}
+	if !c.Scored {
+		rr.Role = "unscored"
+	}
+
+	system := cisIdentSystem
+	if strings.HasPrefix(c.ID, "V-") {
+		system = stigIdentSystem
+	}
+	rr.Idents = []xccdfIdent{{System: system, Value: c.ID}}
+
+	if c.State == WAIVED {
+		rr.Override = &xccdfOverride{
+			Time:      ts,
+			Authority: "kube-bench waivers",
+			OldResult: xccdfWaivedResult(c),
+			NewResult: rr.Result,
+			Remark:    c.Reason,
+		}
+		if c.Waiver != nil {
+			rr.Override.Authority = c.Waiver.Owner
+		}
+	} else if c.Reason != "" {
+		rr.Messages = append(rr.Messages, xccdfMessage{Severity: "info", Value: c.Reason})
+	}
+	if c.ActualValue != "" {
+		rr.Messages = append(rr.Messages, xccdfMessage{Severity: "info", Value: fmt.Sprintf("Actual: %s", c.ActualValue)})
+	}
+	if c.ExpectedResult != "" {
+		rr.Messages = append(rr.Messages, xccdfMessage{Severity: "info", Value: fmt.Sprintf("Expected: %s", c.ExpectedResult)})
+	}
+	return rr
+}
+
+// XCCDFResult maps the state of a check to an XCCDF rule result. A WARN is
+// notchecked when the check is manual or has no tests, a fail when an
+// unscored test failed, and an error when the audit could not be run.
+func XCCDFResult(c *Check) string {
+	switch c.State {
+	case PASS:
+		return "pass"
+	case FAIL:
+		return "fail"
+	case WARN:
+		switch {
+		case c.Type == MANUAL || isNoTestsReason(c.Reason):
+			return "notchecked"
+		case c.Reason == "" && c.ExpectedResult != "":
+			return "fail"
+		default:
+			return "error"
+		}
+	case INFO, WAIVED:
+		return "informational"
+	default:
+		return "unknown"
+	}
+}
+
+// xccdfWaivedResult returns the XCCDF result of a waived check before it was
+// waived, or fail when the waiver does not record the state.
+func xccdfWaivedResult(c *Check) string {
+	if c.Waiver == nil || c.Waiver.State == "" {
+		return "fail"
+	}
+	was := *c
+	was.State = c.Waiver.State
+	// The reason is the waiver's; the check's own reason was replaced.
+	was.Reason = ""
+	return XCCDFResult(&was)
+}
+
+func isNoTestsReason(reason string) bool {
+	switch reason {
+	case "There are no tests", "No tests defined", "Test marked as a manual test":
+		return true
+	}
+	return false
+}
+
+// xccdfSeverity maps a check severity to the XCCDF severity enumeration.
+func xccdfSeverity(severity string) string {
+	switch s := strings.ToLower(severity); s {
+	case "critical":
+		return "high"
+	case "info", "low", "medium", "high":
+		return s
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright © 2017-2019 KhulnaSoft Security Software Ltd. <info@khulnasoft.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXCCDFResult(t *testing.T) {
+	cases := []struct {
+		check    Check
+		expected string
+	}{
+		{check: Check{State: PASS}, expected: "pass"},
+		{check: Check{State: FAIL, Scored: true}, expected: "fail"},
+		{check: Check{State: WARN, Type: MANUAL, Reason: "Test marked as a manual test"}, expected: "notchecked"},
+		{check: Check{State: WARN, Reason: "There are no tests"}, expected: "notchecked"},
+		{check: Check{State: WARN, ActualValue: "777", ExpectedResult: "600"}, expected: "fail"},
+		{check: Check{State: WARN, Reason: "failed to run: exit status 1"}, expected: "error"},
+		{check: Check{State: INFO}, expected: "informational"},
+		{check: Check{State: WAIVED}, expected: "informational"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, XCCDFResult(&c.check), "%+v", c.check)
+	}
+}
+
+func TestXCCDF(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	controls := []*Controls{{
+		Version: "eks-stig-kubernetes-v1r6",
+		Type:    NODE,
+		Groups: []*Group{{
+			ID: "3.1",
+			Checks: []*Check{
+				{ID: "V-242387", State: FAIL, Scored: true, Severity: "high", ActualValue: "readOnlyPort=10255", ExpectedResult: "readOnlyPort is 0"},
+				{ID: "V-242391", State: PASS, Scored: true, Severity: "high"},
+				{ID: "V-242392", State: WAIVED, Reason: "Waived (was FAIL): managed", Waiver: &Waiver{Owner: "platform", State: FAIL}},
+				{ID: "V-242393", Type: MANUAL, State: WAIVED, Reason: "Waived (was WARN): reviewed", Waiver: &Waiver{Owner: "platform", State: WARN}},
+			},
+		}},
+	}}
+
+	out, err := XCCDF(controls, "worker-1", "v0.10.0", now, false)
+	assert.NoError(t, err)
+
+	var tr xccdfTestResult
+	assert.NoError(t, xml.Unmarshal(out, &tr))
+	assert.Equal(t, XCCDFNamespace, tr.XMLName.Space)
+	assert.Equal(t, "xccdf_com.khulnasoft.kube-bench_testresult_eks-stig-kubernetes-v1r6", tr.ID)
+	assert.Equal(t, "worker-1", tr.Target)
+	assert.Len(t, tr.RuleResults, 4)
+
+	failed := tr.RuleResults[0]
+	assert.Equal(t, "xccdf_com.khulnasoft.kube-bench_rule_eks-stig-kubernetes-v1r6_V-242387", failed.IDRef)
+	assert.Equal(t, "fail", failed.Result)
+	assert.Equal(t, "high", failed.Severity)
+	assert.Equal(t, []xccdfIdent{{System: stigIdentSystem, Value: "V-242387"}}, failed.Idents)
+	assert.Contains(t, failed.Messages, xccdfMessage{Severity: "info", Value: "Actual: readOnlyPort=10255"})
+
+	waived := tr.RuleResults[2]
+	assert.Equal(t, "informational", waived.Result)
+	assert.Equal(t, "unscored", waived.Role)
+	assert.Equal(t, &xccdfOverride{Time: "2024-06-01T12:00:00Z", Authority: "platform", OldResult: "fail", NewResult: "informational", Remark: "Waived (was FAIL): managed"}, waived.Override)
+	assert.Equal(t, "notchecked", tr.RuleResults[3].Override.OldResult)
+
+	assert.Equal(t, xccdfScore{System: "urn:xccdf:scoring:flat-unweighted", Maximum: "2", Value: "1"}, tr.Score)
+
+	out, err = XCCDF(controls, "worker-1", "v0.10.0", now, true)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "<arf:asset-report-collection")
+	assert.Contains(t, string(out), `<arf:report id="xccdf1">`)
+	assert.Contains(t, string(out), `<TestResult xmlns="http://checklists.nist.gov/xccdf/1.2"`)
+	assert.Contains(t, string(out), "<ai:hostname>worker-1</ai:hostname>")
+	assert.Contains(t, string(out), `xmlns:arfvocab="http://scap.nist.gov/specifications/arf/vocabulary/relationships/1.0#"`)
+	assert.Contains(t, string(out), `<core:relationship type="arfvocab:isAbout" subject="xccdf1">`)
+	assert.Contains(t, string(out), "<core:ref>asset0</core:ref>")
+
+	controls = append(controls, &Controls{Version: "cis-1.9"})
+	_, err = XCCDF(controls, "worker-1", "v0.10.0", now, false)
+	assert.Error(t, err)
+}
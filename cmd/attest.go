@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+type attestationList struct {
+	Attestations []check.Attestation `yaml:"attestations"`
+}
+
+func init() {
+	RootCmd.AddCommand(attestCmd)
+	attestCmd.Flags().StringSliceP("targets", "s", []string{},
+		`Specify targets of the benchmark to attest, as for the run command. If no targets are specified, all targets are used.`)
+	attestCmd.Flags().String("reviewer", "", "Name of the reviewer recorded in new attestations")
+	attestCmd.Flags().Int("validity-days", 90, "Number of days new attestations remain valid")
+}
+
+// attestCmd represents the attest command
+var attestCmd = &cobra.Command{
+	Use:   "attest",
+	Short: "Record attestations for manual checks",
+	Long: `Walks through the manual checks of the benchmark, showing their text and remediation,
+and records the result, evidence and reviewer in the file given with --attestations.
+Later runs with --attestations use these results instead of WARN until they expire.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if attestationsFile == "" {
+			exitWithError(fmt.Errorf("the --attestations flag is required to know where to record attestations"))
+		}
+
+		targets, err := cmd.Flags().GetStringSlice("targets")
+		if err != nil {
+			exitWithError(fmt.Errorf("unable to get `targets` from command line :%v", err))
+		}
+		reviewer, _ := cmd.Flags().GetString("reviewer")
+		validityDays, _ := cmd.Flags().GetInt("validity-days")
+		if validityDays < 1 {
+			exitWithError(fmt.Errorf("--validity-days must be at least 1"))
+		}
+
+		bv, err := getBenchmarkVersion(kubeVersion, benchmarkVersion, getPlatformInfo(), viper.GetViper())
+		if err != nil {
+			exitWithOutcome(outcomeBenchmarkNotDetected, fmt.Errorf("unable to get benchmark version. error: %v", err))
+		}
+
+		err = mergeConfig(filepath.Join(cfgDir, bv))
+		if err != nil {
+			exitWithOutcome(outcomeConfigNotFound, fmt.Errorf("Error in mergeConfig: %v\n", err))
+		}
+
+		yamlFiles, err := getTestYamlFiles(targets, bv)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		filter, err := NewRunFilter(filterOpts)
+		if err != nil {
+			exitWithError(fmt.Errorf("error setting up run filter: %v", err))
+		}
+
+		var attestations []check.Attestation
+		if _, err := os.Stat(attestationsFile); err == nil {
+			attestations, err = loadAttestations(attestationsFile)
+			if err != nil {
+				exitWithError(err)
+			}
+		}
+
+		in := bufio.NewReader(os.Stdin)
+	walk:
+		for _, yamlFile := range yamlFiles {
+			_, name := filepath.Split(yamlFile)
+			controls, err := loadControls(check.NodeType(strings.Split(name, ".")[0]), yamlFile, detecetedKubeVersion)
+			if err != nil {
+				exitOnError(err)
+			}
+			for _, g := range controls.Groups {
+				for _, c := range g.Checks {
+					if c.Type != check.MANUAL || !filter(g, c) {
+						continue
+					}
+
+					prev := findAttestation(attestations, c.ID, controls.Version)
+					a, err := promptAttestation(in, os.Stdout, c, prev, controls.Version, reviewer, validityDays, time.Now())
+					if err == io.EOF {
+						break walk
+					}
+					if err != nil {
+						exitWithError(err)
+					}
+					if a != nil {
+						attestations = setAttestation(attestations, *a)
+						if err := saveAttestations(attestationsFile, attestations); err != nil {
+							exitWithError(err)
+						}
+					}
+				}
+			}
+		}
+	},
+}
+
+// attestationRunner wraps a check.Runner and applies attestations to manual checks.
+type attestationRunner struct {
+	runner       check.Runner
+	attestations []check.Attestation
+	benchmark    string
+	now          func() time.Time
+}
+
+func newAttestationRunner(runner check.Runner, attestations []check.Attestation, benchmark string) check.Runner {
+	return &attestationRunner{
+		runner:       runner,
+		attestations: attestations,
+		benchmark:    benchmark,
+		now:          time.Now,
+	}
+}
+
+// Run runs the check and, if it is a manual check with a current attestation, reports the attested result.
+func (r *attestationRunner) Run(c *check.Check) check.State {
+	state := r.runner.Run(c)
+	if c.Type != check.MANUAL {
+		return state
+	}
+
+	a := findAttestation(r.attestations, c.ID, r.benchmark)
+	if a == nil {
+		return state
+	}
+
+	attested := *a
+	expires, _ := parseExpiryDate(attested.Expires)
+	if !r.now().Before(expires) {
+		glog.Warningf("attestation for check %s by %s expired on %s, reporting %s", c.ID, attested.Reviewer, attested.Expires, state)
+		attested.Expired = true
+		c.Attestation = &attested
+		return state
+	}
+
+	c.Attestation = &attested
+	c.Reason = fmt.Sprintf("Attested by %s on %s: %s", attested.Reviewer, attested.Date, attested.Evidence)
+	c.State = attested.Status
+	return c.State
+}
+
+// findAttestation returns the attestation for a check, preferring one recorded
+// for the given benchmark over one that applies to any benchmark.
+func findAttestation(attestations []check.Attestation, id, benchmark string) *check.Attestation {
+	var found *check.Attestation
+	for i := range attestations {
+		a := &attestations[i]
+		if a.ID != id {
+			continue
+		}
+		if a.Benchmark == benchmark {
+			return a
+		}
+		if a.Benchmark == "" {
+			found = a
+		}
+	}
+	return found
+}
+
+// setAttestation adds an attestation, replacing any previous one for the same check and benchmark.
+func setAttestation(attestations []check.Attestation, a check.Attestation) []check.Attestation {
+	for i := range attestations {
+		if attestations[i].ID == a.ID && attestations[i].Benchmark == a.Benchmark {
+			attestations[i] = a
+			return attestations
+		}
+	}
+	return append(attestations, a)
+}
+
+// promptAttestation shows a manual check and asks the operator for its result.
+// It returns nil if the check is skipped.
+func promptAttestation(in *bufio.Reader, out io.Writer, c *check.Check, prev *check.Attestation, benchmark, reviewer string, validityDays int, now time.Time) (*check.Attestation, error) {
+	fmt.Fprintf(out, "\n[%s] %s %s\n", strings.ToUpper(check.MANUAL), c.ID, c.Text)
+	if c.Remediation != "" {
+		fmt.Fprintf(out, "Remediation:\n%s\n", strings.TrimRight(c.Remediation, "\n"))
+	}
+	if prev != nil {
+		fmt.Fprintf(out, "Current attestation: %s by %s on %s (expires %s): %s\n", prev.Status, prev.Reviewer, prev.Date, prev.Expires, prev.Evidence)
+	}
+
+	var status check.State
+	for status == "" {
+		answer, err := prompt(in, out, "Result [p]ass, [f]ail, [s]kip", "s")
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(answer) {
+		case "p", "pass":
+			status = check.PASS
+		case "f", "fail":
+			status = check.FAIL
+		case "s", "skip":
+			return nil, nil
+		default:
+			fmt.Fprintf(out, "Unrecognized answer %q\n", answer)
+		}
+	}
+
+	evidence, err := prompt(in, out, "Evidence", "")
+	if err != nil {
+		return nil, err
+	}
+	if reviewer == "" && prev != nil {
+		reviewer = prev.Reviewer
+	}
+	for {
+		reviewer, err = prompt(in, out, "Reviewer", reviewer)
+		if err != nil {
+			return nil, err
+		}
+		if reviewer != "" {
+			break
+		}
+		fmt.Fprintln(out, "A reviewer is required")
+	}
+
+	// The expiry date is the last day the attestation applies, and today is
+	// the first of the validity days.
+	expires := now.AddDate(0, 0, validityDays-1)
+	return &check.Attestation{
+		ID:        c.ID,
+		Benchmark: benchmark,
+		Status:    status,
+		Evidence:  evidence,
+		Reviewer:  reviewer,
+		Date:      now.Format(expiryDateLayout),
+		Expires:   expires.Format(expiryDateLayout),
+	}, nil
+}
+
+func prompt(in *bufio.Reader, out io.Writer, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", question)
+	}
+
+	answer, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		answer = def
+	}
+	return answer, nil
+}
+
+// loadAttestations reads and validates an attestations YAML file.
+func loadAttestations(path string) ([]check.Attestation, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening attestations file %s: %v", path, err)
+	}
+
+	var f attestationList
+	if err := yaml.Unmarshal(in, &f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attestations file %s: %v", path, err)
+	}
+
+	for i, a := range f.Attestations {
+		if isEmpty(a.ID) {
+			return nil, fmt.Errorf("attestation #%d in %s has no id", i+1, path)
+		}
+		switch {
+		case strings.EqualFold(string(a.Status), string(check.PASS)):
+			f.Attestations[i].Status = check.PASS
+		case strings.EqualFold(string(a.Status), string(check.FAIL)):
+			f.Attestations[i].Status = check.FAIL
+		default:
+			return nil, fmt.Errorf("attestation for %s in %s has status %q, expected %s or %s", a.ID, path, a.Status, check.PASS, check.FAIL)
+		}
+		if isEmpty(a.Reviewer) {
+			return nil, fmt.Errorf("attestation for %s in %s has no reviewer", a.ID, path)
+		}
+		if _, err := parseExpiryDate(a.Expires); err != nil {
+			return nil, fmt.Errorf("attestation for %s in %s has an invalid expiry date %q: %v", a.ID, path, a.Expires, err)
+		}
+	}
+
+	glog.V(1).Infof("Loaded %d attestations from %s", len(f.Attestations), path)
+	return f.Attestations, nil
+}
+
+func saveAttestations(path string, attestations []check.Attestation) error {
+	out, err := yaml.Marshal(attestationList{Attestations: attestations})
+	if err != nil {
+		return fmt.Errorf("failed to marshal attestations: %v", err)
+	}
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("failed to write attestations file %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestationRunner(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	attestations := []check.Attestation{
+		{ID: "5.1.1", Status: check.PASS, Evidence: "reviewed bindings", Reviewer: "alice", Date: "2024-05-01", Expires: "2024-08-01"},
+		{ID: "5.1.2", Benchmark: "cis-1.8", Status: check.FAIL, Evidence: "x", Reviewer: "bob", Date: "2024-05-01", Expires: "2024-08-01"},
+		{ID: "5.1.3", Status: check.FAIL, Evidence: "x", Reviewer: "bob", Date: "2023-05-01", Expires: "2023-08-01"},
+	}
+
+	cases := []struct {
+		name          string
+		check         *check.Check
+		expected      check.State
+		expectExpired bool
+		expectAttest  bool
+	}{
+		{name: "attested manual check", check: &check.Check{ID: "5.1.1", Type: check.MANUAL}, expected: check.PASS, expectAttest: true},
+		{name: "attestation for another benchmark", check: &check.Check{ID: "5.1.2", Type: check.MANUAL}, expected: check.WARN},
+		{name: "expired attestation", check: &check.Check{ID: "5.1.3", Type: check.MANUAL}, expected: check.WARN, expectAttest: true, expectExpired: true},
+		{name: "automated check is not attested", check: &check.Check{ID: "5.1.1"}, expected: check.WARN},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newAttestationRunner(stateRunner{state: check.WARN}, attestations, "cis-1.9").(*attestationRunner)
+			r.now = func() time.Time { return now }
+
+			assert.Equal(t, c.expected, r.Run(c.check))
+			if !c.expectAttest {
+				assert.Nil(t, c.check.Attestation)
+				return
+			}
+			assert.Equal(t, c.expectExpired, c.check.Attestation.Expired)
+		})
+	}
+}
+
+func TestPromptAttestation(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := &check.Check{ID: "5.1.1", Text: "Ensure that the cluster-admin role is only used where required", Remediation: "Remove bindings"}
+
+	var out bytes.Buffer
+	in := bufio.NewReader(strings.NewReader("maybe\np\nonly break-glass accounts\n\n"))
+	a, err := promptAttestation(in, &out, c, nil, "cis-1.9", "alice", 30, now)
+	assert.NoError(t, err)
+	assert.Equal(t, &check.Attestation{
+		ID:        "5.1.1",
+		Benchmark: "cis-1.9",
+		Status:    check.PASS,
+		Evidence:  "only break-glass accounts",
+		Reviewer:  "alice",
+		Date:      "2024-06-01",
+		Expires:   "2024-06-30",
+	}, a)
+
+	// An attestation valid for 30 days applies until the end of its 30th day.
+	r := newAttestationRunner(stateRunner{state: check.WARN}, []check.Attestation{*a}, "cis-1.9").(*attestationRunner)
+	r.now = func() time.Time { return time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC) }
+	assert.Equal(t, check.PASS, r.Run(&check.Check{ID: "5.1.1", Type: check.MANUAL}))
+	r.now = func() time.Time { return time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC) }
+	assert.Equal(t, check.WARN, r.Run(&check.Check{ID: "5.1.1", Type: check.MANUAL}))
+	assert.Contains(t, out.String(), "Remove bindings")
+	assert.Contains(t, out.String(), `Unrecognized answer "maybe"`)
+
+	a, err = promptAttestation(bufio.NewReader(strings.NewReader("\n")), &out, c, nil, "cis-1.9", "alice", 30, now)
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+}
+
+func TestSaveAndLoadAttestations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attestations.yaml")
+	a := check.Attestation{ID: "5.1.1", Status: check.FAIL, Evidence: "e", Reviewer: "r", Date: "2024-06-01", Expires: "2024-09-01"}
+
+	attestations := setAttestation(nil, a)
+	a.Evidence = "updated"
+	attestations = setAttestation(attestations, a)
+	assert.Len(t, attestations, 1)
+
+	assert.NoError(t, saveAttestations(path, attestations))
+	loaded, err := loadAttestations(path)
+	assert.NoError(t, err)
+	assert.Equal(t, attestations, loaded)
+}
+
+func TestLoadAttestationsStatusCase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attestations.yaml")
+	content := "attestations:\n- id: 5.1.1\n  status: pass\n  reviewer: r\n  expires: 2030-01-01\n- id: 5.1.2\n  status: Fail\n  reviewer: r\n  expires: 2030-01-01\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadAttestations(path)
+	assert.NoError(t, err)
+	assert.Equal(t, check.PASS, loaded[0].Status)
+	assert.Equal(t, check.FAIL, loaded[1].Status)
+
+	if err := os.WriteFile(path, []byte("attestations:\n- id: 5.1.1\n  status: skip\n  reviewer: r\n  expires: 2030-01-01\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = loadAttestations(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// checkTextSuffix is the assessment status at the end of a check's text,
+// which changes between benchmark versions.
+var checkTextSuffix = regexp.MustCompile(`(?i)\s*\((automated|manual|scored|not scored)\)\s*$`)
+
+// checkIdentity identifies a check independently of the benchmark version, by
+// its target and its text without punctuation or assessment status, so that
+// e.g. "Ensure that the --anonymous-auth argument is set to false (Automated)"
+// matches "Ensure that the anonymous-auth argument is set to false (Scored)".
+func checkIdentity(target check.NodeType, text string) string {
+	text = checkTextSuffix.ReplaceAllString(strings.ToLower(text), "")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	return string(target) + "/" + strings.Join(words, " ")
+}
+
+// checkIDKey identifies a check within a benchmark.
+func checkIDKey(benchmark string, target check.NodeType, id string) string {
+	return benchmark + "/" + string(target) + "/" + id
+}
+
+// baseline is the failed checks of a baseline result.
+type baseline struct {
+	identities map[string]bool
+	ids        map[string]bool
+}
+
+func newBaseline(results *check.OverallControls) *baseline {
+	b := &baseline{identities: make(map[string]bool), ids: make(map[string]bool)}
+	if results == nil {
+		return b
+	}
+	for _, controls := range results.Controls {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				if c.State != check.FAIL {
+					continue
+				}
+				if identity := checkIdentity(controls.Type, c.Text); identity != "" {
+					b.identities[identity] = true
+				}
+				b.ids[checkIDKey(controls.Version, controls.Type, c.ID)] = true
+			}
+		}
+	}
+	return b
+}
+
+// contains reports whether the check failed in the baseline: a check with the
+// same identity, or else the same benchmark, target and ID, failed.
+func (b *baseline) contains(benchmark string, target check.NodeType, c *check.Check) bool {
+	if identity := checkIdentity(target, c.Text); identity != "" && b.identities[identity] {
+		return true
+	}
+	return b.ids[checkIDKey(benchmark, target, c.ID)]
+}
+
+// loadBaseline reads a baseline saved with --update-baseline, or any JSON
+// results. With --update-baseline, a missing baseline is empty.
+func loadBaseline(path string) (*baseline, error) {
+	results, err := readResults(path)
+	if err != nil {
+		if updateBaseline && errors.Is(err, fs.ErrNotExist) {
+			glog.V(1).Infof("Baseline %s does not exist yet", path)
+			return newBaseline(nil), nil
+		}
+		return nil, fmt.Errorf("error loading baseline: %v", err)
+	}
+	return newBaseline(results), nil
+}
+
+// baselineRunner wraps a check.Runner and marks the failed checks that also
+// failed in the baseline as known.
+type baselineRunner struct {
+	runner    check.Runner
+	baseline  *baseline
+	benchmark string
+	target    check.NodeType
+}
+
+func newBaselineRunner(runner check.Runner, b *baseline, controls *check.Controls) check.Runner {
+	return &baselineRunner{runner: runner, baseline: b, benchmark: controls.Version, target: controls.Type}
+}
+
+// Run runs the check and, if it failed and is in the baseline, marks it as known.
+func (r *baselineRunner) Run(c *check.Check) check.State {
+	state := r.runner.Run(c)
+	c.Known = state == check.FAIL && r.baseline.contains(r.benchmark, r.target, c)
+	return state
+}
+
+// knownFailures counts the failed checks of the controls that are in the baseline.
+func knownFailures(controls *check.Controls) int {
+	var known int
+	for _, g := range controls.Groups {
+		for _, c := range g.Checks {
+			if c.State == check.FAIL && c.Known {
+				known++
+			}
+		}
+	}
+	return known
+}
+
+// writeBaseline writes the results as the new baseline.
+func writeBaseline(controlsCollection []*check.Controls, path string) error {
+	out, err := json.Marshal(newOverallControls(controlsCollection))
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %v", err)
+	}
+	if err := writeFileAtomically(path, out); err != nil {
+		return fmt.Errorf("failed to write baseline %s: %v", path, err)
+	}
+	glog.V(1).Infof("Updated baseline %s", path)
+	return nil
+}
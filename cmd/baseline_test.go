@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIdentity(t *testing.T) {
+	assert.Equal(t,
+		checkIdentity(check.NODE, "Ensure that the --anonymous-auth argument is set to false (Automated)"),
+		checkIdentity(check.NODE, "Ensure that the anonymous-auth argument is set to false (Scored)"))
+	assert.Equal(t, "node/ensure that the anonymous auth argument is set to false",
+		checkIdentity(check.NODE, "Ensure that the anonymous-auth argument is set to false (Not Scored)"))
+	assert.NotEqual(t,
+		checkIdentity(check.NODE, "Ensure that the anonymous-auth argument is set to false"),
+		checkIdentity(check.MASTER, "Ensure that the anonymous-auth argument is set to false"))
+	assert.Empty(t, checkIdentity(check.NODE, " (Manual)"))
+}
+
+func baselineTestResults() *check.OverallControls {
+	return &check.OverallControls{Controls: []*check.Controls{{
+		Version: "cis-1.8",
+		Type:    check.NODE,
+		Groups: []*check.Group{{ID: "4.2", Checks: []*check.Check{
+			{ID: "4.2.1", Text: "Ensure that the --anonymous-auth argument is set to false (Automated)", State: check.FAIL},
+			{ID: "4.2.2", Text: "Ensure that the --authorization-mode argument is not set to AlwaysAllow (Automated)", State: check.PASS},
+			{ID: "4.2.3", State: check.FAIL},
+		}}},
+	}}}
+}
+
+func TestBaseline(t *testing.T) {
+	b := newBaseline(baselineTestResults())
+
+	cases := []struct {
+		name      string
+		benchmark string
+		target    check.NodeType
+		check     *check.Check
+		known     bool
+	}{
+		{name: "same text in another benchmark", benchmark: "cis-1.5", target: check.NODE,
+			check: &check.Check{ID: "4.2.9", Text: "Ensure that the anonymous-auth argument is set to false (Scored)"}, known: true},
+		{name: "same text in another target", benchmark: "cis-1.8", target: check.MASTER,
+			check: &check.Check{ID: "4.2.1", Text: "Ensure that the --anonymous-auth argument is set to false (Automated)"}},
+		{name: "passed in the baseline", benchmark: "cis-1.8", target: check.NODE,
+			check: &check.Check{ID: "4.2.2", Text: "Ensure that the --authorization-mode argument is not set to AlwaysAllow (Automated)"}},
+		{name: "same ID without text", benchmark: "cis-1.8", target: check.NODE,
+			check: &check.Check{ID: "4.2.3"}, known: true},
+		{name: "same ID in another benchmark", benchmark: "cis-1.9", target: check.NODE,
+			check: &check.Check{ID: "4.2.3"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.known, b.contains(c.benchmark, c.target, c.check))
+		})
+	}
+}
+
+func TestBaselineRunner(t *testing.T) {
+	b := newBaseline(baselineTestResults())
+	controls := &check.Controls{Version: "cis-1.8", Type: check.NODE}
+
+	c := &check.Check{ID: "4.2.3"}
+	assert.Equal(t, check.FAIL, newBaselineRunner(stateRunner{state: check.FAIL}, b, controls).Run(c))
+	assert.True(t, c.Known)
+
+	assert.Equal(t, check.WARN, newBaselineRunner(stateRunner{state: check.WARN}, b, controls).Run(c))
+	assert.False(t, c.Known, "only failed checks are known")
+
+	other := &check.Check{ID: "4.2.4"}
+	newBaselineRunner(stateRunner{state: check.FAIL}, b, controls).Run(other)
+	assert.False(t, other.Known)
+}
+
+func TestLoadBaseline(t *testing.T) {
+	defer func(update bool) { updateBaseline = update }(updateBaseline)
+	missing := filepath.Join(t.TempDir(), "baseline.json")
+
+	updateBaseline = false
+	_, err := loadBaseline(missing)
+	assert.Error(t, err)
+
+	updateBaseline = true
+	b, err := loadBaseline(missing)
+	require.NoError(t, err)
+	assert.Empty(t, b.ids, "a baseline that is being created is empty")
+}
+
+func TestWriteBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, writeBaseline(baselineTestResults().Controls, path))
+
+	b, err := loadBaseline(path)
+	require.NoError(t, err)
+	assert.True(t, b.contains("cis-1.8", check.NODE, &check.Check{ID: "4.2.3"}))
+}
+
+func TestKnownFailures(t *testing.T) {
+	defer func(code int, on string) { exitCode, failOn = code, on }(exitCode, failOn)
+	exitCode = 2
+
+	controls := baselineTestResults().Controls
+	controls[0].Summary = check.Summary{Pass: 1, Fail: 2}
+	checks := controls[0].Groups[0].Checks
+	checks[0].Known = true
+
+	assert.Equal(t, 1, knownFailures(controls[0]))
+	assert.Equal(t, 2, exitCodeSelection(controls), "a new failure trips --exit-code")
+
+	checks[2].Known = true
+	assert.Equal(t, 0, exitCodeSelection(controls), "known failures don't trip --exit-code")
+
+	failOn = "FAIL"
+	assert.Equal(t, 0, exitCodeSelection(controls), "known failures don't match --fail-on")
+	checks[2].Known = false
+	assert.Equal(t, 2, exitCodeSelection(controls))
+}
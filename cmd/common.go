@@ -20,8 +20,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/golang/glog"
@@ -29,50 +27,85 @@ import (
 	"github.com/spf13/viper"
 )
 
-// NewRunFilter constructs a Predicate based on FilterOpts which determines whether tested Checks should be run or not.
-func NewRunFilter(opts FilterOpts) (check.Predicate, error) {
-	if opts.CheckList != "" && opts.GroupList != "" {
-		return nil, fmt.Errorf("group option and check option can't be used together")
+// runChecks runs the checks of a target and adds the results to controlsCollection.
+func runChecks(nodetype check.NodeType, testYamlFile, detectedVersion string) error {
+	controls, err := loadControls(nodetype, testYamlFile, detectedVersion)
+	if err != nil {
+		return err
 	}
+	targetControlsFiles[nodetype] = testYamlFile
 
-	var groupIDs map[string]bool
-	if opts.GroupList != "" {
-		groupIDs = cleanIDs(opts.GroupList)
+	filter, err := NewRunFilter(filterOpts)
+	if err != nil {
+		return fmt.Errorf("error setting up run filter: %v", err)
 	}
-
-	var checkIDs map[string]bool
-	if opts.CheckList != "" {
-		checkIDs = cleanIDs(opts.CheckList)
+	if err := runControls(controls, filter); err != nil {
+		return err
 	}
+	controlsCollection = append(controlsCollection, controls)
+	return nil
+}
 
-	return func(g *check.Group, c *check.Check) bool {
-		test := true
-		if len(groupIDs) > 0 {
-			_, ok := groupIDs[g.ID]
-			test = test && ok
+// runControls runs the checks of loaded controls for which the filter returns true.
+func runControls(controls *check.Controls, filter check.Predicate) error {
+	nodetype := controls.Type
+	runner := check.NewRunner()
+	if attestationsFile != "" {
+		attestations, err := loadAttestations(attestationsFile)
+		if err != nil {
+			return err
 		}
-
-		if len(checkIDs) > 0 {
-			_, ok := checkIDs[c.ID]
-			test = test && ok
+		runner = newAttestationRunner(runner, attestations, controls.Version)
+	}
+	if waiversFile != "" {
+		waivers, err := loadWaivers(waiversFile)
+		if err != nil {
+			return err
+		}
+		scope := waiverScope{node: getNodeName(), benchmark: controls.Version, target: string(nodetype)}
+		runner = newWaiverRunner(runner, waivers, scope)
+	}
+	if baselineFile != "" {
+		b, err := loadBaseline(baselineFile)
+		if err != nil {
+			return err
 		}
+		runner = newBaselineRunner(runner, b, controls)
+	}
 
-		test = test && (opts.Scored && c.Scored || opts.Unscored && !c.Scored)
+	if streamFmt {
+		runner = newStreamRunner(runner, controls)
+	}
 
-		return test
-	}, nil
+	skip, err := NewSkipFilter(skipIds)
+	if err != nil {
+		return fmt.Errorf("error setting up skip filter: %v", err)
+	}
+	for _, g := range controls.Groups {
+		for _, c := range g.Checks {
+			if skip(g, c) {
+				c.Type = check.SKIP
+			}
+		}
+	}
+
+	emitEvent(streamEvent{Event: eventTargetStarted, Benchmark: controls.Version, Target: nodetype})
+	summary := controls.RunChecks(runner, filter, nil)
+	emitEvent(streamEvent{Event: eventTargetFinished, Benchmark: controls.Version, Target: nodetype, Summary: &summary})
+	return nil
 }
 
-func runChecks(nodetype check.NodeType, testYamlFile, detectedVersion string) {
+// loadControls reads a controls file, substitutes the binaries and files found on
+// this node for the variables it contains and returns the resulting controls.
+func loadControls(nodetype check.NodeType, testYamlFile, detectedVersion string) (*check.Controls, error) {
 	// Verify config file was loaded into Viper during Cobra sub-command initialization.
 	if configFileError != nil {
-		colorPrint(check.FAIL, fmt.Sprintf("Failed to read config file: %v\n", configFileError))
-		os.Exit(1)
+		return nil, withOutcome(outcomeConfigNotFound, fmt.Errorf("Failed to read config file: %v", configFileError))
 	}
 
 	in, err := os.ReadFile(testYamlFile)
 	if err != nil {
-		exitWithError(fmt.Errorf("error opening %s test file: %v", testYamlFile, err))
+		return nil, fmt.Errorf("error opening %s test file: %v", testYamlFile, err)
 	}
 
 	glog.V(1).Info(fmt.Sprintf("Using test file: %s\n", testYamlFile))
@@ -80,8 +113,7 @@ func runChecks(nodetype check.NodeType, testYamlFile, detectedVersion string) {
 	// Get the viper config for this section of tests
 	typeConf := viper.Sub(string(nodetype))
 	if typeConf == nil {
-		colorPrint(check.FAIL, fmt.Sprintf("No config settings for %s\n", string(nodetype)))
-		os.Exit(1)
+		return nil, withOutcome(outcomeConfigNotFound, fmt.Errorf("No config settings for %s", string(nodetype)))
 	}
 
 	// Get the set of executables we need for this section of the tests
@@ -91,11 +123,20 @@ func runChecks(nodetype check.NodeType, testYamlFile, detectedVersion string) {
 		glog.V(1).Info(fmt.Sprintf("failed to get a set of executables needed for tests: %v", err))
 	}
 
-	confmap := getFiles(typeConf, "config")
-	svcmap := getFiles(typeConf, "service")
-	kubeconfmap := getFiles(typeConf, "kubeconfig")
-	cafilemap := getFiles(typeConf, "ca")
-	datadirmap := getFiles(typeConf, "datadir")
+	files := make(map[string]map[string]string)
+	for _, fileType := range []string{"config", "service", "kubeconfig", "ca", "datadir"} {
+		filemap, err := getFiles(typeConf, fileType)
+		if err != nil {
+			return nil, err
+		}
+		files[fileType] = filemap
+	}
+	confmap := files["config"]
+	svcmap := files["service"]
+	kubeconfmap := files["kubeconfig"]
+	cafilemap := files["ca"]
+	datadirmap := files["datadir"]
+	recordComponents(nodetype, binmap, files)
 
 	// Variable substitutions. Replace all occurrences of variables in controls files.
 	s := string(in)
@@ -108,19 +149,11 @@ func runChecks(nodetype check.NodeType, testYamlFile, detectedVersion string) {
 
 	controls, err := check.NewControls(nodetype, []byte(s), detectedVersion)
 	if err != nil {
-		exitWithError(fmt.Errorf("error setting up %s controls: %v", nodetype, err))
-	}
-
-	runner := check.NewRunner()
-	filter, err := NewRunFilter(filterOpts)
-	if err != nil {
-		exitWithError(fmt.Errorf("error setting up run filter: %v", err))
+		return nil, fmt.Errorf("error setting up %s controls: %v", nodetype, err)
 	}
 
 	generateDefaultEnvAudit(controls, binSubs)
-
-	controls.RunChecks(runner, filter, parseSkipIds(skipIds))
-	controlsCollection = append(controlsCollection, controls)
+	return controls, nil
 }
 
 func generateDefaultEnvAudit(controls *check.Controls, binSubs []string) {
@@ -147,16 +180,6 @@ func generateDefaultEnvAudit(controls *check.Controls, binSubs []string) {
 	}
 }
 
-func parseSkipIds(skipIds string) map[string]bool {
-	skipIdMap := make(map[string]bool, 0)
-	if skipIds != "" {
-		for _, id := range strings.Split(skipIds, ",") {
-			skipIdMap[strings.Trim(id, " ")] = true
-		}
-	}
-	return skipIdMap
-}
-
 // colorPrint outputs the state in a specific colour, along with a message string
 func colorPrint(state check.State, s string) {
 	colors[state].Printf("[%s] ", state)
@@ -171,9 +194,13 @@ func prettyPrint(r *check.Controls, summary check.Summary) {
 		for _, g := range r.Groups {
 			colorPrint(check.INFO, fmt.Sprintf("%s %s\n", g.ID, g.Text))
 			for _, c := range g.Checks {
-				colorPrint(c.State, fmt.Sprintf("%s %s\n", c.ID, c.Text))
+				if c.Known {
+					colorPrint(c.State, fmt.Sprintf("%s %s (known)\n", c.ID, c.Text))
+				} else {
+					colorPrint(c.State, fmt.Sprintf("%s %s\n", c.ID, c.Text))
+				}
 
-				if includeTestOutput && c.State == check.FAIL && len(c.ActualValue) > 0 {
+				if includeTestOutput && (c.State == check.FAIL || c.State == check.WAIVED) && len(c.ActualValue) > 0 {
 					printRawOutput(c.ActualValue)
 				}
 			}
@@ -205,13 +232,27 @@ func prettyPrint(r *check.Controls, summary check.Summary) {
 		}
 	}
 
+	// Print waivers so accepted risks stay visible.
+	if !noRemediations && summary.Waived > 0 {
+		colors[check.WAIVED].Printf("== Waivers %s ==\n", r.Type)
+		for _, g := range r.Groups {
+			for _, c := range g.Checks {
+				if c.State == check.WAIVED && c.Waiver != nil {
+					fmt.Printf("%s %s (owner: %s, ticket: %s, expires: %s)\n", c.ID, c.Waiver.Justification, c.Waiver.Owner, c.Waiver.Ticket, c.Waiver.Expires)
+				}
+			}
+		}
+		fmt.Println()
+	}
+
 	// Print summary setting output color to highest severity.
 	if !noSummary {
-		printSummary(summary, string(r.Type))
+		score := check.ComputeScore([]*check.Controls{r}, scoreWeights())
+		printSummary(summary, knownFailures(r), score.Overall, string(r.Type))
 	}
 }
 
-func printSummary(summary check.Summary, sectionName string) {
+func printSummary(summary check.Summary, known int, score float64, sectionName string) {
 	var res check.State
 	if summary.Fail > 0 {
 		res = check.FAIL
@@ -222,15 +263,21 @@ func printSummary(summary check.Summary, sectionName string) {
 	}
 
 	colors[res].Printf("== Summary %s ==\n", sectionName)
-	fmt.Printf("%d checks PASS\n%d checks FAIL\n%d checks WARN\n%d checks INFO\n\n",
-		summary.Pass, summary.Fail, summary.Warn, summary.Info,
-	)
+	fmt.Printf("%d checks PASS\n%d checks FAIL\n", summary.Pass, summary.Fail)
+	if known > 0 {
+		fmt.Printf("%d checks FAIL that are known from the baseline\n", known)
+	}
+	fmt.Printf("%d checks WARN\n%d checks INFO\n", summary.Warn, summary.Info)
+	if summary.Waived > 0 {
+		fmt.Printf("%d checks WAIVED\n", summary.Waived)
+	}
+	fmt.Printf("Compliance score: %.2f%%\n\n", score)
 }
 
 // loadConfig finds the correct config dir based on the kubernetes version,
 // merges any specific config.yaml file found with the main config
 // and returns the benchmark file to use.
-func loadConfig(nodetype check.NodeType, benchmarkVersion string) string {
+func loadConfig(nodetype check.NodeType, benchmarkVersion string) (string, error) {
 	var file string
 	var err error
 
@@ -251,13 +298,13 @@ func loadConfig(nodetype check.NodeType, benchmarkVersion string) string {
 
 	path, err := getConfigFilePath(benchmarkVersion, file)
 	if err != nil {
-		exitWithError(fmt.Errorf("can't find %s controls file in %s: %v", nodetype, cfgDir, err))
+		return "", withOutcome(outcomeConfigNotFound, fmt.Errorf("can't find %s controls file in %s: %v", nodetype, cfgDir, err))
 	}
 
 	// Merge version-specific config if any.
 	mergeConfig(path)
 
-	return filepath.Join(path, file)
+	return filepath.Join(path, file), nil
 }
 
 func mergeConfig(path string) error {
@@ -315,6 +362,7 @@ func loadTargetMapping(v *viper.Viper) (map[string][]string, error) {
 
 func getBenchmarkVersion(kubeVersion, benchmarkVersion string, platform Platform, v *viper.Viper) (bv string, err error) {
 	detecetedKubeVersion = "none"
+	benchmarkReason = "set with --benchmark"
 	if !isEmpty(kubeVersion) && !isEmpty(benchmarkVersion) {
 		return "", fmt.Errorf("It is an error to specify both --version and --benchmark flags")
 	}
@@ -322,10 +370,12 @@ func getBenchmarkVersion(kubeVersion, benchmarkVersion string, platform Platform
 		benchmarkVersion = getPlatformBenchmarkVersion(platform)
 		if !isEmpty(benchmarkVersion) {
 			detecetedKubeVersion = benchmarkVersion
+			benchmarkReason = fmt.Sprintf("detected platform %s %s", platform.Name, platform.Version)
 		}
 	}
 
 	if isEmpty(benchmarkVersion) {
+		benchmarkReason = fmt.Sprintf("mapped from Kubernetes version %s set with --version", kubeVersion)
 		if isEmpty(kubeVersion) {
 			kv, err := getKubeVersion()
 			if err != nil {
@@ -333,6 +383,7 @@ func getBenchmarkVersion(kubeVersion, benchmarkVersion string, platform Platform
 			}
 			kubeVersion = kv.BaseVersion()
 			detecetedKubeVersion = kubeVersion
+			benchmarkReason = fmt.Sprintf("mapped from detected Kubernetes version %s", kubeVersion)
 		}
 
 		kubeToBenchmarkMap, err := loadVersionMapping(v)
@@ -384,94 +435,102 @@ func isThisNodeRunning(nodeType check.NodeType) bool {
 	return true
 }
 
-func exitCodeSelection(controlsCollection []*check.Controls) int {
-	for _, control := range controlsCollection {
-		if control.Fail > 0 {
-			return exitCode
+func writeOutput(controlsCollection []*check.Controls) {
+	finishScanMetadata()
+	emitScanFinished(controlsCollection)
+
+	sinks, err := getOutputSinks()
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := writeOutputs(controlsCollection, sinks); err != nil {
+		exitWithError(err)
+	}
+	if updateBaseline {
+		if err := writeBaseline(controlsCollection, baselineFile); err != nil {
+			exitWithError(err)
 		}
 	}
-
-	return 0
-}
-
-func writeOutput(controlsCollection []*check.Controls) {
-	sort.Slice(controlsCollection, func(i, j int) bool {
-		iid, _ := strconv.Atoi(controlsCollection[i].ID)
-		jid, _ := strconv.Atoi(controlsCollection[j].ID)
-		return iid < jid
-	})
-	if junitFmt {
-		writeJunitOutput(controlsCollection)
-		return
-	}
-	if jsonFmt {
-		writeJSONOutput(controlsCollection)
-		return
-	}
-	if pgSQL {
-		writePgsqlOutput(controlsCollection)
-		return
-	}
-	if aSFF {
-		writeASFFOutput(controlsCollection)
-		return
-	}
-	writeStdoutOutput(controlsCollection)
 }
 
-func writeJSONOutput(controlsCollection []*check.Controls) {
+func writeJSONOutput(controlsCollection []*check.Controls, destination string) error {
 	var out []byte
 	var err error
 	if !noTotals {
-		var totals check.OverallControls
-		totals.Controls = controlsCollection
-		totals.Totals = getSummaryTotals(controlsCollection)
-		out, err = json.Marshal(totals)
+		out, err = json.Marshal(newOverallControls(controlsCollection))
 	} else {
 		out, err = json.Marshal(controlsCollection)
 	}
 	if err != nil {
-		exitWithError(fmt.Errorf("failed to output in JSON format: %v", err))
+		return fmt.Errorf("failed to output in JSON format: %v", err)
+	}
+	return printOutput(string(out), destination)
+}
+
+// newOverallControls returns the results of the scan in progress with their totals and score.
+func newOverallControls(controlsCollection []*check.Controls) *check.OverallControls {
+	score := check.ComputeScore(controlsCollection, scoreWeights())
+	return &check.OverallControls{
+		SchemaVersion: check.SchemaVersion,
+		Metadata:      scanMetadata,
+		Controls:      controlsCollection,
+		Totals:        getSummaryTotals(controlsCollection),
+		Score:         &score,
 	}
-	printOutput(string(out), outputFile)
 }
 
-func writeJunitOutput(controlsCollection []*check.Controls) {
+func writeJunitOutput(controlsCollection []*check.Controls, destination string) error {
 	// QuickFix for issue https://github.com/khulnasoft-lab/kube-bench/issues/883
 	// Should consider to deprecate of switch to using Junit template
 	prefix := "<testsuites>\n"
 	suffix := "\n</testsuites>"
+	var properties []check.JUnitProperty
+	if scanMetadata != nil {
+		properties = scanMetadata.JUnitProperties()
+	}
 	var outputAllControls []byte
 	for _, controls := range controlsCollection {
-		tempOut, err := controls.JUnit()
+		tempOut, err := controls.JUnit(properties...)
 		outputAllControls = append(outputAllControls[:], tempOut[:]...)
 		if err != nil {
-			exitWithError(fmt.Errorf("failed to output in JUnit format: %v", err))
+			return fmt.Errorf("failed to output in JUnit format: %v", err)
 		}
 	}
-	printOutput(prefix+string(outputAllControls)+suffix, outputFile)
+	return printOutput(prefix+string(outputAllControls)+suffix, destination)
+}
+
+func writeSARIFOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := check.SARIF(controlsCollection, resultKubeBenchVersion())
+	if err != nil {
+		return fmt.Errorf("failed to output in SARIF format: %v", err)
+	}
+	return printOutput(string(out), destination)
 }
 
-func writePgsqlOutput(controlsCollection []*check.Controls) {
+func writePgsqlOutput(controlsCollection []*check.Controls, _ string) error {
 	for _, controls := range controlsCollection {
 		out, err := controls.JSON()
 		if err != nil {
-			exitWithError(fmt.Errorf("failed to output in Postgresql format: %v", err))
+			return fmt.Errorf("failed to output in Postgresql format: %v", err)
+		}
+		if err := savePgsql(string(out), scanMetadata); err != nil {
+			return err
 		}
-		savePgsql(string(out))
 	}
+	return nil
 }
 
-func writeASFFOutput(controlsCollection []*check.Controls) {
+func writeASFFOutput(controlsCollection []*check.Controls, _ string) error {
 	for _, controls := range controlsCollection {
 		out, err := controls.ASFF()
 		if err != nil {
-			exitWithError(fmt.Errorf("failed to format findings as ASFF: %v", err))
+			return fmt.Errorf("failed to format findings as ASFF: %v", err)
 		}
 		if err := writeFinding(out); err != nil {
-			exitWithError(fmt.Errorf("failed to output to ASFF: %v", err))
+			return fmt.Errorf("failed to output to ASFF: %v", err)
 		}
 	}
+	return nil
 }
 
 func writeStdoutOutput(controlsCollection []*check.Controls) {
@@ -480,7 +539,12 @@ func writeStdoutOutput(controlsCollection []*check.Controls) {
 		prettyPrint(controls, summary)
 	}
 	if !noTotals {
-		printSummary(getSummaryTotals(controlsCollection), "total")
+		score := check.ComputeScore(controlsCollection, scoreWeights())
+		var known int
+		for _, controls := range controlsCollection {
+			known += knownFailures(controls)
+		}
+		printSummary(getSummaryTotals(controlsCollection), known, score.Overall, "total")
 	}
 }
 
@@ -492,6 +556,7 @@ func getSummaryTotals(controlsCollection []*check.Controls) check.Summary {
 		totalSummary.Warn = totalSummary.Warn + summary.Warn
 		totalSummary.Pass = totalSummary.Pass + summary.Pass
 		totalSummary.Info = totalSummary.Info + summary.Info
+		totalSummary.Waived = totalSummary.Waived + summary.Waived
 	}
 	return totalSummary
 }
@@ -514,15 +579,15 @@ func writeOutputToFile(output string, outputFile string) error {
 	return w.Flush()
 }
 
-func printOutput(output string, outputFile string) {
-	if outputFile == "" {
+func printOutput(output string, outputFile string) error {
+	if outputFile == "" || outputFile == "-" {
 		fmt.Println(output)
-	} else {
-		err := writeOutputToFile(output, outputFile)
-		if err != nil {
-			exitWithError(fmt.Errorf("Failed to write to output file %s: %v", outputFile, err))
-		}
+		return nil
 	}
+	if err := writeOutputToFile(output, outputFile); err != nil {
+		return fmt.Errorf("Failed to write to output file %s: %v", outputFile, err)
+	}
+	return nil
 }
 
 // validTargets helps determine if the targets
@@ -39,105 +39,30 @@ type JsonOutputFormatNoTotals struct {
 	Controls []*check.Controls `json:"Controls"`
 }
 
-func TestParseSkipIds(t *testing.T) {
-	skipMap := parseSkipIds("4.12,4.13,5")
-	_, fourTwelveExists := skipMap["4.12"]
-	_, fourThirteenExists := skipMap["4.13"]
-	_, fiveExists := skipMap["5"]
-	_, other := skipMap["G1"]
-	assert.True(t, fourThirteenExists)
-	assert.True(t, fourTwelveExists)
-	assert.True(t, fiveExists)
-	assert.False(t, other)
-}
-
-func TestNewRunFilter(t *testing.T) {
-	type TestCase struct {
-		Name       string
-		FilterOpts FilterOpts
-		Group      *check.Group
-		Check      *check.Check
-
-		Expected bool
-	}
-
-	testCases := []TestCase{
-		{
-			Name:       "Should return true when scored flag is enabled and check is scored",
-			FilterOpts: FilterOpts{Scored: true, Unscored: false},
-			Group:      &check.Group{},
-			Check:      &check.Check{Scored: true},
-			Expected:   true,
-		},
-		{
-			Name:       "Should return false when scored flag is enabled and check is not scored",
-			FilterOpts: FilterOpts{Scored: true, Unscored: false},
-			Group:      &check.Group{},
-			Check:      &check.Check{Scored: false},
-			Expected:   false,
-		},
-
-		{
-			Name:       "Should return true when unscored flag is enabled and check is not scored",
-			FilterOpts: FilterOpts{Scored: false, Unscored: true},
-			Group:      &check.Group{},
-			Check:      &check.Check{Scored: false},
-			Expected:   true,
-		},
-		{
-			Name:       "Should return false when unscored flag is enabled and check is scored",
-			FilterOpts: FilterOpts{Scored: false, Unscored: true},
-			Group:      &check.Group{},
-			Check:      &check.Check{Scored: true},
-			Expected:   false,
-		},
-
-		{
-			Name:       "Should return true when group flag contains group's ID",
-			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "G1,G2,G3"},
-			Group:      &check.Group{ID: "G2"},
-			Check:      &check.Check{},
-			Expected:   true,
-		},
-		{
-			Name:       "Should return false when group flag doesn't contain group's ID",
-			FilterOpts: FilterOpts{GroupList: "G1,G3"},
-			Group:      &check.Group{ID: "G2"},
-			Check:      &check.Check{},
-			Expected:   false,
-		},
-
-		{
-			Name:       "Should return true when check flag contains check's ID",
-			FilterOpts: FilterOpts{Scored: true, Unscored: true, CheckList: "C1,C2,C3"},
-			Group:      &check.Group{},
-			Check:      &check.Check{ID: "C2"},
-			Expected:   true,
-		},
-		{
-			Name:       "Should return false when check flag doesn't contain check's ID",
-			FilterOpts: FilterOpts{CheckList: "C1,C3"},
-			Group:      &check.Group{},
-			Check:      &check.Check{ID: "C2"},
-			Expected:   false,
-		},
+func TestRunChecksUnreadableConfig(t *testing.T) {
+	defer viper.Reset()
+	restoreStat := statFunc
+	defer func() { statFunc = restoreStat }()
+	statFunc = func(name string) (os.FileInfo, error) {
+		if name == "/etc/kubernetes/kubelet.conf" {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrPermission}
+		}
+		return os.Stat(name)
 	}
+	viper.Set("node", map[string]interface{}{
+		"components": []string{"kubelet"},
+		"kubelet":    map[string]interface{}{"confs": []string{"/etc/kubernetes/kubelet.conf"}},
+	})
 
-	for _, testCase := range testCases {
-		t.Run(testCase.Name, func(t *testing.T) {
-			filter, _ := NewRunFilter(testCase.FilterOpts)
-			assert.Equal(t, testCase.Expected, filter(testCase.Group, testCase.Check))
-		})
+	controlsFile := filepath.Join(t.TempDir(), "node.yaml")
+	if err := os.WriteFile(controlsFile, []byte(controlsText("Kubelet config is secure")), 0o600); err != nil {
+		t.Fatal(err)
 	}
 
-	t.Run("Should return error when both group and check flags are used", func(t *testing.T) {
-		// given
-		opts := FilterOpts{GroupList: "G1", CheckList: "C1"}
-		// when
-		_, err := NewRunFilter(opts)
-		// then
-		assert.EqualError(t, err, "group option and check option can't be used together")
-	})
+	// An unreadable config file fails the scan instead of exiting.
+	err := runChecks(check.NODE, controlsFile, "")
+	assert.ErrorContains(t, err, "error looking for file /etc/kubernetes/kubelet.conf")
+	assert.ErrorIs(t, err, os.ErrPermission)
 }
 
 func TestIsMaster(t *testing.T) {
@@ -631,6 +556,39 @@ func TestExitCodeSelection(t *testing.T) {
 
 	exitCodeFailure := exitCodeSelection(controlsCollectionWithFailures)
 	assert.Equal(t, 10, exitCodeFailure)
+
+	t.Run("compliance gates", func(t *testing.T) {
+		defer func() {
+			failUnder = 0
+			failOn = ""
+		}()
+		controlsCollection := []*check.Controls{{
+			Type: check.NODE,
+			Groups: []*check.Group{{ID: "4.2", Checks: []*check.Check{
+				{ID: "4.2.1", State: check.PASS},
+				{ID: "4.2.2", State: check.PASS},
+				{ID: "4.2.3", State: check.PASS},
+				{ID: "4.2.4", State: check.FAIL, Severity: "medium"},
+				{ID: "4.2.5", State: check.WARN},
+			}}},
+			Summary: check.Summary{Pass: 3, Fail: 1, Warn: 1},
+		}}
+
+		failUnder = 70
+		assert.Equal(t, 0, exitCodeSelection(controlsCollection))
+		failUnder = 80
+		assert.Equal(t, 10, exitCodeSelection(controlsCollection))
+
+		failUnder = 0
+		failOn = "high"
+		assert.Equal(t, 0, exitCodeSelection(controlsCollection))
+		failOn = "low"
+		assert.Equal(t, 10, exitCodeSelection(controlsCollection))
+		failOn = "warn"
+		assert.Equal(t, 10, exitCodeSelection(controlsCollection))
+		failOn = "info"
+		assert.Equal(t, 0, exitCodeSelection(controlsCollection))
+	})
 }
 
 func TestGenerationDefaultEnvAudit(t *testing.T) {
@@ -691,12 +649,12 @@ func TestPrintSummary(t *testing.T) {
 	rescueStdout := os.Stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
-	printSummary(resultTotals, "totals")
+	printSummary(resultTotals, 0, 80.33, "totals")
 	w.Close()
 	out, _ := io.ReadAll(r)
 	os.Stdout = rescueStdout
 
-	assert.Contains(t, string(out), "49 checks PASS\n12 checks FAIL\n14 checks WARN\n0 checks INFO\n\n")
+	assert.Contains(t, string(out), "49 checks PASS\n12 checks FAIL\n14 checks WARN\n0 checks INFO\nCompliance score: 80.33%\n\n")
 }
 
 func TestPrettyPrintNoSummary(t *testing.T) {
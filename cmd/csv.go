@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+var csvHeader = []string{
+	"node", "benchmark", "target", "group_id", "group_text", "check_id", "check_text",
+	"scored", "type", "state", "actual_value", "expected_result", "reason", "remediation",
+}
+
+// renderCSV writes one row per check. encoding/csv quotes the fields that
+// need it, such as multi-line remediations and audit output.
+func renderCSV(node string, controlsCollection []*check.Controls) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				err := w.Write([]string{
+					node,
+					controls.Version,
+					string(controls.Type),
+					g.ID,
+					g.Text,
+					c.ID,
+					c.Text,
+					strconv.FormatBool(c.Scored),
+					c.Type,
+					string(c.State),
+					c.ActualValue,
+					c.ExpectedResult,
+					c.Reason,
+					c.Remediation,
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeCSVOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := renderCSV(resultNode(), controlsCollection)
+	if err != nil {
+		return fmt.Errorf("failed to output in CSV format: %v", err)
+	}
+	return printOutput(strings.TrimSuffix(string(out), "\n"), destination)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCSV(t *testing.T) {
+	controls := []*check.Controls{{
+		Version: "cis-1.9",
+		Type:    check.MASTER,
+		Groups: []*check.Group{{
+			ID:   "1.1",
+			Text: "Control Plane Node Configuration Files",
+			Checks: []*check.Check{{
+				ID:             "1.1.1",
+				Text:           `Ensure that the "API server" file permissions are set`,
+				Scored:         true,
+				State:          check.FAIL,
+				ActualValue:    "permissions=777\npermissions=644",
+				ExpectedResult: "600",
+				Remediation:    "Run the below command, for example,\nchmod 600 /etc/kubernetes/manifests/kube-apiserver.yaml",
+			}},
+		}},
+	}}
+
+	out, err := renderCSV("master-1", controls)
+	assert.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		csvHeader,
+		{
+			"master-1", "cis-1.9", "master", "1.1", "Control Plane Node Configuration Files", "1.1.1",
+			`Ensure that the "API server" file permissions are set`, "true", "", "FAIL",
+			"permissions=777\npermissions=644", "600", "",
+			"Run the below command, for example,\nchmod 600 /etc/kubernetes/manifests/kube-apiserver.yaml",
+		},
+	}, records)
+}
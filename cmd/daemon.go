@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	daemonInterval    time.Duration
+	daemonJitter      time.Duration
+	daemonDriftOutput string
+	daemonWatch       bool
+)
+
+func init() {
+	RootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().StringSliceP("targets", "s", []string{},
+		`Specify targets of the benchmark to run, as for the run command.
+	If no targets are specified, run the targets whose components run on the node.`)
+	daemonCmd.Flags().DurationVar(&daemonInterval, "interval", time.Hour, "Time between scans. With --watch, 0 disables scheduled scans after the first")
+	daemonCmd.Flags().DurationVar(&daemonJitter, "jitter", 5*time.Minute, "Delay each scan, including the first, by a random duration up to this, so that the nodes of a DaemonSet don't all scan at once")
+	daemonCmd.Flags().StringVar(&daemonDriftOutput, "drift-output", "", "File to append drift events to, one JSON object per line. Defaults to stdout, unless drift outputs are given with --output")
+	daemonCmd.Flags().BoolVar(&daemonWatch, "watch", false, "Watch the config, service, kubeconfig, CA and controls files, and run the checks that use a file again as soon as it changes")
+}
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Scan the node at an interval and report checks that drift between scans",
+	Long: `Keeps kube-bench running, scanning the node every --interval and writing a
+check_drifted event for each check whose state or actual value differs from the
+previous scan. With --watch, the checks that use a config, service, kubeconfig,
+CA or controls file are also run again as soon as the file changes. The results
+of every scan are written to the outputs given with --output or in the config
+file, if any.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		targets, err := cmd.Flags().GetStringSlice("targets")
+		if err != nil {
+			exitWithError(fmt.Errorf("unable to get `targets` from command line :%v", err))
+		}
+		if daemonInterval < 0 || (daemonInterval == 0 && !daemonWatch) {
+			exitWithError(fmt.Errorf("--interval must be positive"))
+		}
+		if daemonJitter < 0 {
+			exitWithError(fmt.Errorf("--jitter must not be negative"))
+		}
+
+		out, closeOutputs, err := driftOutputs()
+		if err != nil {
+			exitWithError(err)
+		}
+		defer closeOutputs()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		d := &driftDaemon{scan: nodeScanner(targets), out: out}
+		var changes chan []string
+		if daemonWatch {
+			watcher, err := newFileWatcher()
+			if err != nil {
+				exitWithError(err)
+			}
+			defer watcher.Close()
+			d.watcher = watcher
+			changes = make(chan []string)
+			go watcher.run(ctx, watchSettleTime, changes)
+		}
+		d.run(ctx, daemonInterval, daemonJitter, changes)
+	},
+}
+
+// writeDriftOutput is the writer of the drift output format. The daemon writes
+// drift events to the drift outputs as scans finish; results write nothing.
+func writeDriftOutput(controlsCollection []*check.Controls, destination string) error {
+	return nil
+}
+
+// driftOutputs opens where drift events are written: the --drift-output file
+// and the drift outputs given with --output or in the config file, or stdout
+// if there are none. The returned function closes the files.
+func driftOutputs() (io.Writer, func(), error) {
+	var destinations []string
+	if daemonDriftOutput != "" {
+		destinations = append(destinations, daemonDriftOutput)
+	}
+	optional := make(map[string]bool)
+	if len(outputSpecs) > 0 || viper.IsSet("outputs") {
+		sinks, err := getOutputSinks()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, s := range sinks {
+			if s.Format == "drift" {
+				destinations = append(destinations, s.Destination)
+				optional[s.Destination] = optional[s.Destination] || s.Optional
+			}
+		}
+	}
+	if len(destinations) == 0 {
+		return os.Stdout, func() {}, nil
+	}
+
+	var writers []io.Writer
+	var files []*os.File
+	closeFiles := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	seen := make(map[string]bool)
+	for _, d := range destinations {
+		if d == "-" {
+			d = ""
+		}
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		if d == "" {
+			writers = append(writers, os.Stdout)
+			continue
+		}
+		f, err := os.OpenFile(d, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			if optional[d] {
+				glog.Warningf("failed to open optional drift output: %v", err)
+				continue
+			}
+			closeFiles()
+			return nil, nil, fmt.Errorf("failed to open drift output: %v", err)
+		}
+		files = append(files, f)
+		writers = append(writers, f)
+	}
+	return io.MultiWriter(writers...), closeFiles, nil
+}
+
+// driftDaemon scans repeatedly and reports the checks that differ from the
+// previous successful scan.
+type driftDaemon struct {
+	scan scanner
+	out  io.Writer
+	// watcher, if set, is given the files the results depend on after each scan.
+	watcher *fileWatcher
+
+	previous   *check.OverallControls
+	previousID string
+}
+
+// run scans every interval until the context is done, or only once if the
+// interval is 0. Each scheduled scan, including the first, is delayed by up to
+// jitter. The checks that use the files received from changes are run again
+// as soon as they are received.
+func (d *driftDaemon) run(ctx context.Context, interval, jitter time.Duration, changes <-chan []string) {
+	timer := time.NewTimer(jitterDelay(jitter))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			d.scanOnce(d.scan)
+			if d.watcher != nil && d.previous != nil {
+				d.watcher.setFiles(watchedFiles(d.previous, targetControlsFiles))
+			}
+			if interval > 0 {
+				timer.Reset(interval + jitterDelay(jitter))
+			}
+		case changed := <-changes:
+			if d.previous == nil {
+				continue
+			}
+			glog.V(1).Infof("Files changed: %s", strings.Join(changed, ", "))
+			d.scanOnce(changedScanner(d.previous, targetControlsFiles, changed))
+		}
+	}
+}
+
+// scanOnce runs a scan and writes a drift event for each check that differs
+// from the previous scan. The first scan only sets what later ones are
+// compared with, and a failed scan is reported and then ignored.
+func (d *driftDaemon) scanOnce(scan scanner) []check.CheckChange {
+	id := uuid.NewString()
+	result, err := safeScan(scan, id)
+	if err != nil {
+		glog.Warningf("scan %s failed: %v", id, err)
+		writeEvent(d.out, streamEvent{Event: eventScanFailed, ScanID: id, Error: err.Error()})
+		return nil
+	}
+
+	previous, previousID := d.previous, d.previousID
+	d.previous, d.previousID = result, id
+	if previous == nil {
+		glog.V(1).Infof("Scan %s finished, later scans are compared with it", id)
+		return nil
+	}
+
+	changes := check.CompareResults(previous.Controls, result.Controls)
+	node := getNodeName()
+	for i, c := range changes {
+		writeEvent(d.out, streamEvent{
+			Event:          eventCheckDrifted,
+			ScanID:         id,
+			PreviousScanID: previousID,
+			Node:           node,
+			Benchmark:      c.Benchmark,
+			Target:         c.Target,
+			Change:         &changes[i],
+		})
+	}
+	glog.V(1).Infof("Scan %s found %d checks that drifted since scan %s", id, len(changes), previousID)
+	return changes
+}
+
+// jitterDelay returns a random duration up to jitter.
+func jitterDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return rand.N(jitter)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceScanner returns the node results of prometheusTestControls with
+// the given states of check 4.1.1, one per scan. An empty state fails the scan.
+func sequenceScanner(states ...check.State) scanner {
+	return func(id string) (*check.OverallControls, error) {
+		if len(states) == 0 {
+			return nil, errors.New("no more scans")
+		}
+		state := states[0]
+		states = states[1:]
+		if state == "" {
+			return nil, errors.New("no benchmark")
+		}
+		cc := prometheusTestControls()
+		cc[0].Groups[0].Checks[0].State = state
+		return &check.OverallControls{Controls: cc}, nil
+	}
+}
+
+func readEvents(t *testing.T, b *bytes.Buffer) []streamEvent {
+	t.Helper()
+	var events []streamEvent
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e streamEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+	b.Reset()
+	return events
+}
+
+func TestDriftDaemonScanOnce(t *testing.T) {
+	var out bytes.Buffer
+	d := &driftDaemon{scan: sequenceScanner(check.PASS, check.PASS, "", check.FAIL), out: &out}
+
+	assert.Empty(t, d.scanOnce(d.scan), "the first scan is only compared with later ones")
+	assert.Empty(t, readEvents(t, &out))
+	firstID := d.previousID
+
+	assert.Empty(t, d.scanOnce(d.scan), "nothing changed")
+	assert.Empty(t, readEvents(t, &out))
+	secondID := d.previousID
+	assert.NotEqual(t, firstID, secondID)
+
+	assert.Empty(t, d.scanOnce(d.scan))
+	events := readEvents(t, &out)
+	require.Len(t, events, 1)
+	assert.Equal(t, eventScanFailed, events[0].Event)
+	assert.Equal(t, "no benchmark", events[0].Error)
+	assert.Equal(t, secondID, d.previousID, "a failed scan is not compared with")
+
+	changes := d.scanOnce(d.scan)
+	require.Len(t, changes, 1)
+	assert.Equal(t, check.Regressed, changes[0].Kind)
+
+	events = readEvents(t, &out)
+	require.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, eventCheckDrifted, e.Event)
+	assert.Equal(t, d.previousID, e.ScanID)
+	assert.Equal(t, secondID, e.PreviousScanID)
+	assert.Equal(t, "cis-1.9", e.Benchmark)
+	assert.Equal(t, check.NODE, e.Target)
+	require.NotNil(t, e.Change)
+	assert.Equal(t, "4.1.1", e.Change.ID)
+	assert.Equal(t, check.PASS, e.Change.OldState)
+	assert.Equal(t, check.FAIL, e.Change.NewState)
+}
+
+func TestDriftDaemonRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var scans int
+	d := &driftDaemon{out: &bytes.Buffer{}}
+	d.scan = func(id string) (*check.OverallControls, error) {
+		scans++
+		if scans == 3 {
+			cancel()
+		}
+		return &check.OverallControls{Controls: prometheusTestControls()}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx, time.Millisecond, time.Millisecond, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the daemon did not stop")
+	}
+	assert.Equal(t, 3, scans)
+}
+
+func TestJitterDelay(t *testing.T) {
+	assert.Zero(t, jitterDelay(0))
+	assert.Zero(t, jitterDelay(-time.Second))
+	for i := 0; i < 100; i++ {
+		d := jitterDelay(time.Second)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.Less(t, d, time.Second)
+	}
+}
+
+func TestDriftOutputs(t *testing.T) {
+	restoreSpecs, restoreDrift := outputSpecs, daemonDriftOutput
+	defer func() { outputSpecs, daemonDriftOutput = restoreSpecs, restoreDrift }()
+
+	dir := t.TempDir()
+	flagFile := filepath.Join(dir, "flag.ndjson")
+	sinkFile := filepath.Join(dir, "sink.ndjson")
+	daemonDriftOutput = flagFile
+	outputSpecs = []string{"json=" + filepath.Join(dir, "results.json"), "drift=" + sinkFile, "drift=" + filepath.Join(dir, "missing", "drift.ndjson") + ",optional"}
+
+	out, closeOutputs, err := driftOutputs()
+	require.NoError(t, err)
+	d := &driftDaemon{scan: sequenceScanner(check.PASS, check.FAIL), out: out}
+	d.scanOnce(d.scan)
+	d.scanOnce(d.scan)
+	closeOutputs()
+
+	for _, f := range []string{flagFile, sinkFile} {
+		data, err := os.ReadFile(f)
+		require.NoError(t, err)
+		events := readEvents(t, bytes.NewBuffer(data))
+		require.Len(t, events, 1, f)
+		assert.Equal(t, eventCheckDrifted, events[0].Event)
+	}
+
+	outputSpecs = []string{"drift=" + filepath.Join(dir, "missing", "drift.ndjson")}
+	_, _, err = driftOutputs()
+	assert.Error(t, err, "a drift output that can't be opened fails unless it is optional")
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
 	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -74,19 +76,19 @@ func (c *PsqlConnInfo) toString() string {
 	)
 }
 
-func savePgsql(jsonInfo string) {
+func savePgsql(jsonInfo string, metadata *check.Metadata) error {
 	var hostname string
 	if value := viper.GetString("K8S_HOST"); value != "" {
 		// Adhere to the ScanHost column definition below
 		if len(value) > 63 {
-			exitWithError(fmt.Errorf("%s_K8S_HOST value's length must be less than 63 chars", envVarsPrefix))
+			return fmt.Errorf("%s_K8S_HOST value's length must be less than 63 chars", envVarsPrefix)
 		}
 
 		hostname = value
 	} else {
 		host, err := os.Hostname()
 		if err != nil {
-			exitWithError(fmt.Errorf("received error looking up hostname: %s", err))
+			return fmt.Errorf("received error looking up hostname: %s", err)
 		}
 
 		hostname = host
@@ -94,23 +96,47 @@ func savePgsql(jsonInfo string) {
 
 	PsqlConnInfo, err := getPsqlConnInfo()
 	if err != nil {
-		exitWithError(err)
+		return err
 	}
 
 	db, err := gorm.Open(postgres.Open(PsqlConnInfo.toString()), &gorm.Config{})
 	if err != nil {
-		exitWithError(fmt.Errorf("received error connecting to database: %s", err))
+		return fmt.Errorf("received error connecting to database: %s", err)
 	}
 
 	timestamp := time.Now()
 	type ScanResult struct {
 		gorm.Model
-		ScanHost string    `gorm:"type:varchar(63) not null"` // https://www.ietf.org/rfc/rfc1035.txt
-		ScanTime time.Time `gorm:"not null"`
-		ScanInfo string    `gorm:"type:jsonb not null"`
+		ScanHost         string    `gorm:"type:varchar(63) not null"` // https://www.ietf.org/rfc/rfc1035.txt
+		ScanTime         time.Time `gorm:"not null"`
+		ScanInfo         string    `gorm:"type:jsonb not null"`
+		ScanID           string    `gorm:"type:varchar(36);index"`
+		KubeBenchVersion string
+		Benchmark        string
+		Platform         string
+		ScanDuration     float64
+		ScanMetadata     *string `gorm:"type:jsonb"`
+	}
+
+	result := ScanResult{ScanHost: hostname, ScanTime: timestamp, ScanInfo: jsonInfo}
+	if metadata != nil {
+		out, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode scan metadata: %v", err)
+		}
+		m := string(out)
+		result.ScanID = metadata.ScanID
+		result.KubeBenchVersion = metadata.KubeBenchVersion
+		result.Benchmark = metadata.Benchmark
+		result.Platform = metadata.Platform
+		result.ScanDuration = metadata.DurationSeconds
+		result.ScanMetadata = &m
 	}
 
 	db.Debug().AutoMigrate(&ScanResult{})
-	db.Save(&ScanResult{ScanHost: hostname, ScanTime: timestamp, ScanInfo: jsonInfo})
+	if err := db.Save(&result).Error; err != nil {
+		return fmt.Errorf("received error saving scan result: %s", err)
+	}
 	glog.V(2).Info(fmt.Sprintf("successfully stored result to: %s", PsqlConnInfo.Host))
+	return nil
 }
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/cobra"
+)
+
+// diffKinds are the kinds of changes in the order they are reported.
+var diffKinds = []check.ChangeKind{check.Regressed, check.Improved, check.Changed, check.Added, check.Removed}
+
+// diffMaxValue is the length at which actual values are truncated in the
+// Markdown report.
+const diffMaxValue = 200
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD.json NEW.json",
+	Short: "Compares the JSON results of two scans.",
+	Long: `Compares the JSON results of two scans, saved with --json with or without
+--nototals, and reports the checks that regressed, improved, were added or
+removed, and whose actual value changed. Checks are matched by benchmark,
+target and ID. The report is text, or JSON with --json or Markdown with
+--markdown, and is written to --outputfile if set. kube-bench exits with
+--exit-code, or 1, if a check regressed.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldResults, err := readResults(args[0])
+		if err != nil {
+			exitWithError(err)
+		}
+		newResults, err := readResults(args[1])
+		if err != nil {
+			exitWithError(err)
+		}
+
+		d := newResultDiff(args[0], oldResults, args[1], newResults)
+		var out string
+		switch {
+		case jsonFmt:
+			b, err := json.Marshal(d)
+			if err != nil {
+				exitWithError(fmt.Errorf("failed to output in JSON format: %v", err))
+			}
+			out = string(b)
+		case markdownFmt:
+			out = renderDiffMarkdown(d)
+		default:
+			out = renderDiffText(d)
+		}
+		if err := printOutput(out, outputFile); err != nil {
+			exitWithError(err)
+		}
+
+		if d.Summary.Regressed > 0 {
+			glog.Flush()
+			os.Exit(regressionExitCode())
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+}
+
+// regressionExitCode is the exit code for when checks regressed: the exit
+// code for failed checks, or 1 if that is 0, as with the compliance gates.
+func regressionExitCode() int {
+	if code := exitCodeFor(outcomeFail); code != 0 {
+		return code
+	}
+	return 1
+}
+
+// diffSource describes one of the results compared.
+type diffSource struct {
+	File   string        `json:"file"`
+	ScanID string        `json:"scan_id,omitempty"`
+	Totals check.Summary `json:"totals"`
+	Score  float64       `json:"score"`
+}
+
+// newDiffSource describes results with the score they were saved with, or
+// else one computed from them.
+func newDiffSource(file string, results *check.OverallControls) diffSource {
+	s := diffSource{File: file, Totals: results.Totals}
+	if results.Score != nil {
+		s.Score = results.Score.Overall
+	} else {
+		s.Score = check.ComputeScore(results.Controls, scoreWeights()).Overall
+	}
+	if results.Metadata != nil {
+		s.ScanID = results.Metadata.ScanID
+	}
+	return s
+}
+
+// diffSummary counts the changes of each kind.
+type diffSummary struct {
+	Regressed int `json:"regressed"`
+	Improved  int `json:"improved"`
+	Changed   int `json:"changed"`
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+}
+
+// resultDiff is the report of the diff command.
+type resultDiff struct {
+	Old     diffSource          `json:"old"`
+	New     diffSource          `json:"new"`
+	Summary diffSummary         `json:"summary"`
+	Changes []check.CheckChange `json:"changes"`
+}
+
+func newResultDiff(oldFile string, oldResults *check.OverallControls, newFile string, newResults *check.OverallControls) resultDiff {
+	d := resultDiff{
+		Old:     newDiffSource(oldFile, oldResults),
+		New:     newDiffSource(newFile, newResults),
+		Changes: check.CompareResults(oldResults.Controls, newResults.Controls),
+	}
+	if d.Changes == nil {
+		d.Changes = []check.CheckChange{}
+	}
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case check.Regressed:
+			d.Summary.Regressed++
+		case check.Improved:
+			d.Summary.Improved++
+		case check.Changed:
+			d.Summary.Changed++
+		case check.Added:
+			d.Summary.Added++
+		case check.Removed:
+			d.Summary.Removed++
+		}
+	}
+	return d
+}
+
+// changesOf returns the changes of a kind.
+func (d resultDiff) changesOf(kind check.ChangeKind) []check.CheckChange {
+	var changes []check.CheckChange
+	for _, c := range d.Changes {
+		if c.Kind == kind {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// actualValueChanged reports whether a check that is in both results has a
+// different actual value.
+func actualValueChanged(c check.CheckChange) bool {
+	return c.Kind != check.Added && c.Kind != check.Removed && c.OldActualValue != c.NewActualValue
+}
+
+// stateTransition describes the states of a check in the two results.
+func stateTransition(c check.CheckChange) string {
+	switch {
+	case c.Kind == check.Added:
+		return string(c.NewState)
+	case c.Kind == check.Removed:
+		return string(c.OldState)
+	case c.OldState == c.NewState:
+		return string(c.NewState)
+	}
+	return fmt.Sprintf("%s -> %s", c.OldState, c.NewState)
+}
+
+func describeDiffSource(s diffSource) string {
+	desc := s.File
+	if s.ScanID != "" {
+		desc += " (scan " + s.ScanID + ")"
+	}
+	return fmt.Sprintf("%s: %d pass, %d fail, %d warn, %d info, score %.2f%%",
+		desc, s.Totals.Pass, s.Totals.Fail, s.Totals.Warn, s.Totals.Info, s.Score)
+}
+
+func renderDiffText(d resultDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Old: %s\n", describeDiffSource(d.Old))
+	fmt.Fprintf(&b, "New: %s\n", describeDiffSource(d.New))
+
+	for _, kind := range diffKinds {
+		changes := d.changesOf(kind)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n== %s (%d) ==\n", strings.ToUpper(string(kind)), len(changes))
+		for _, c := range changes {
+			fmt.Fprintf(&b, "%s %s [%s %s]: %s\n", c.ID, c.Text, c.Benchmark, c.Target, stateTransition(c))
+			if actualValueChanged(c) {
+				fmt.Fprintf(&b, "\tactual value: %q -> %q\n", c.OldActualValue, c.NewActualValue)
+			}
+		}
+	}
+
+	s := d.Summary
+	fmt.Fprintf(&b, "\n== Summary ==\n%d checks regressed\n%d checks improved\n%d checks changed\n%d checks added\n%d checks removed",
+		s.Regressed, s.Improved, s.Changed, s.Added, s.Removed)
+	return b.String()
+}
+
+func renderDiffMarkdown(d resultDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# kube-bench diff\n\n")
+	fmt.Fprintf(&b, "- **Old:** %s\n", markdownEscape(describeDiffSource(d.Old)))
+	fmt.Fprintf(&b, "- **New:** %s\n\n", markdownEscape(describeDiffSource(d.New)))
+
+	fmt.Fprintf(&b, "| Regressed | Improved | Changed | Added | Removed |\n")
+	fmt.Fprintf(&b, "| ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d |\n",
+		d.Summary.Regressed, d.Summary.Improved, d.Summary.Changed, d.Summary.Added, d.Summary.Removed)
+
+	for _, kind := range diffKinds {
+		changes := d.changesOf(kind)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s%s\n\n", strings.ToUpper(string(kind[:1])), kind[1:])
+		fmt.Fprintf(&b, "| ID | Benchmark | Target | Description | Old | New |\n")
+		fmt.Fprintf(&b, "| --- | --- | --- | --- | --- | --- |\n")
+		for _, c := range changes {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", markdownCell(c.ID), markdownCell(c.Benchmark), c.Target, markdownCell(c.Text),
+				diffMarkdownValue(c, c.OldState, c.OldActualValue), diffMarkdownValue(c, c.NewState, c.NewActualValue))
+		}
+	}
+	return b.String()
+}
+
+// diffMarkdownValue is a state for a table cell, with the actual value if it changed.
+func diffMarkdownValue(c check.CheckChange, state check.State, actual string) string {
+	if state == "" {
+		return "-"
+	}
+	if !actualValueChanged(c) || actual == "" {
+		return string(state)
+	}
+	return fmt.Sprintf("%s: %s", state, markdownCell(truncateValue(actual, diffMaxValue)))
+}
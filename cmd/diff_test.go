@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diffTestResults() (*check.OverallControls, *check.OverallControls) {
+	oldControls := prometheusTestControls()
+	oldControls[0].Groups[0].Checks[0].Text = "Kubelet config permissions"
+	oldControls[0].Groups[0].Checks[0].ActualValue = "600"
+	oldResults := &check.OverallControls{
+		Metadata: &check.Metadata{ScanID: "scan-1"},
+		Controls: oldControls,
+		Totals:   check.Summary{Pass: 1, Fail: 1},
+	}
+
+	newControls := prometheusTestControls()
+	c := newControls[0].Groups[0].Checks
+	c[0].Text = "Kubelet config permissions"
+	c[0].State = check.FAIL
+	c[0].ActualValue = "777"
+	c[1].State = check.PASS
+	newControls[0].Groups[0].Checks = append(c, &check.Check{ID: "4.1.3", Text: "New check", State: check.WARN})
+	newResults := &check.OverallControls{Controls: newControls, Totals: check.Summary{Pass: 1, Fail: 1, Warn: 1}}
+	return oldResults, newResults
+}
+
+func TestNewResultDiff(t *testing.T) {
+	oldResults, newResults := diffTestResults()
+	d := newResultDiff("old.json", oldResults, "new.json", newResults)
+
+	assert.Equal(t, diffSummary{Regressed: 1, Improved: 1, Added: 1}, d.Summary)
+	assert.Equal(t, diffSource{File: "old.json", ScanID: "scan-1", Totals: check.Summary{Pass: 1, Fail: 1}, Score: 50}, d.Old)
+	assert.Equal(t, "new.json", d.New.File)
+	assert.Equal(t, check.Summary{Pass: 1, Fail: 1, Warn: 1}, d.New.Totals)
+	require.Len(t, d.Changes, 3)
+	assert.Equal(t, check.Regressed, d.Changes[0].Kind)
+	assert.Equal(t, "4.1.1", d.Changes[0].ID)
+
+	out, err := json.Marshal(newResultDiff("a.json", oldResults, "a.json", oldResults))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"changes":[]`, "no changes are an empty list rather than null")
+}
+
+func TestRenderDiffText(t *testing.T) {
+	oldResults, newResults := diffTestResults()
+	out := renderDiffText(newResultDiff("old.json", oldResults, "new.json", newResults))
+
+	for _, line := range []string{
+		"Old: old.json (scan scan-1): 1 pass, 1 fail, 0 warn, 0 info, score 50.00%",
+		"New: new.json: 1 pass, 1 fail, 1 warn, 0 info, score 50.00%",
+		"== REGRESSED (1) ==",
+		"4.1.1 Kubelet config permissions [cis-1.9 node]: PASS -> FAIL",
+		"\tactual value: \"600\" -> \"777\"",
+		"== IMPROVED (1) ==",
+		"4.1.2  [cis-1.9 node]: FAIL -> PASS",
+		"== ADDED (1) ==",
+		"4.1.3 New check [cis-1.9 node]: WARN",
+		"1 checks regressed",
+		"0 checks removed",
+	} {
+		assert.Contains(t, out, line)
+	}
+	assert.NotContains(t, out, "CHANGED")
+}
+
+func TestRenderDiffMarkdown(t *testing.T) {
+	oldResults, newResults := diffTestResults()
+	out := renderDiffMarkdown(newResultDiff("old.json", oldResults, "new.json", newResults))
+
+	for _, line := range []string{
+		"# kube-bench diff",
+		"| 1 | 1 | 0 | 1 | 0 |",
+		"## Regressed",
+		"| 4.1.1 | cis-1.9 | node | Kubelet config permissions | PASS: 600 | FAIL: 777 |",
+		"| 4.1.2 | cis-1.9 | node |  | FAIL | PASS |",
+		"| 4.1.3 | cis-1.9 | node | New check | - | WARN |",
+	} {
+		assert.Contains(t, out, line)
+	}
+	assert.False(t, strings.Contains(out, "## Changed"))
+}
+
+func TestDiffMarkdownValue(t *testing.T) {
+	c := check.CheckChange{OldActualValue: "a", NewActualValue: strings.Repeat("é", diffMaxValue+10)}
+	value := diffMarkdownValue(c, check.FAIL, c.NewActualValue)
+	assert.Equal(t, "FAIL: "+strings.Repeat("é", diffMaxValue)+"...", value)
+	assert.True(t, utf8.ValidString(value))
+
+	assert.Equal(t, "-", diffMarkdownValue(c, "", ""))
+	assert.Equal(t, "PASS", diffMarkdownValue(check.CheckChange{}, check.PASS, "x"))
+}
+
+func TestRegressionExitCode(t *testing.T) {
+	defer func(code int) { exitCode = code }(exitCode)
+
+	exitCode = 0
+	assert.Equal(t, 1, regressionExitCode())
+	exitCode = 3
+	assert.Equal(t, 3, regressionExitCode())
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+)
+
+// outcome is a reason for kube-bench to exit that has its own exit code.
+type outcome int
+
+const (
+	outcomeInternalError outcome = iota
+	outcomeConfigNotFound
+	outcomeBenchmarkNotDetected
+	outcomeFail
+	outcomeWarn
+	outcomeWaiverExpired
+)
+
+// exitCodeSetting binds an outcome to its flag and to its key in the exit_codes config section.
+type exitCodeSetting struct {
+	flag      string
+	configKey string
+	value     *int
+	def       int
+	usage     string
+}
+
+var (
+	internalErrorExitCode        int
+	configNotFoundExitCode       int
+	benchmarkNotDetectedExitCode int
+	warnExitCode                 int
+	waiverExpiredExitCode        int
+
+	// exitCodeFlagChanged reports whether an exit code flag was given on the command line.
+	exitCodeFlagChanged = func(name string) bool { return false }
+
+	exitCodeSettings = map[outcome]exitCodeSetting{
+		outcomeInternalError:        {flag: "exit-code-error", configKey: "internal_error", value: &internalErrorExitCode, def: 1, usage: "Specify the exit code for internal errors"},
+		outcomeConfigNotFound:       {flag: "exit-code-config", configKey: "config_not_found", value: &configNotFoundExitCode, def: 1, usage: "Specify the exit code for when the config or controls files can't be found or read"},
+		outcomeBenchmarkNotDetected: {flag: "exit-code-benchmark", configKey: "benchmark_not_detected", value: &benchmarkNotDetectedExitCode, def: 1, usage: "Specify the exit code for when the benchmark version can't be determined"},
+		outcomeFail:                 {flag: "exit-code", configKey: "fail", value: &exitCode, def: 0, usage: "Specify the exit code for when checks fail"},
+		outcomeWarn:                 {flag: "exit-code-warn", configKey: "warn", value: &warnExitCode, def: 0, usage: "Specify the exit code for when checks warn and none fail"},
+		outcomeWaiverExpired:        {flag: "exit-code-waiver-expired", configKey: "waiver_expired", value: &waiverExpiredExitCode, def: 0, usage: "Specify the exit code for when a failed check has an expired waiver and none fail"},
+	}
+)
+
+func init() {
+	for _, o := range []outcome{outcomeFail, outcomeWarn, outcomeWaiverExpired, outcomeInternalError, outcomeConfigNotFound, outcomeBenchmarkNotDetected} {
+		s := exitCodeSettings[o]
+		RootCmd.PersistentFlags().IntVar(s.value, s.flag, s.def, s.usage)
+	}
+	exitCodeFlagChanged = RootCmd.PersistentFlags().Changed
+}
+
+// exitCodeFor returns the exit code of an outcome. Flags take precedence over
+// the exit_codes section of the config file.
+func exitCodeFor(o outcome) int {
+	s := exitCodeSettings[o]
+	if exitCodeFlagChanged(s.flag) {
+		return *s.value
+	}
+	if key := "exit_codes." + s.configKey; viper.IsSet(key) {
+		return viper.GetInt(key)
+	}
+	return *s.value
+}
+
+// exitWithOutcome prints the error, if any, and exits with the code of the outcome.
+func exitWithOutcome(o outcome, err error) {
+	if err != nil {
+		emitEvent(streamEvent{Event: eventScanFailed, Error: err.Error()})
+		fmt.Fprintf(os.Stderr, "\n%v\n", err)
+	}
+	// flush before exit non-zero
+	glog.Flush()
+	os.Exit(exitCodeFor(o))
+}
+
+func exitWithError(err error) {
+	exitWithOutcome(outcomeInternalError, err)
+}
+
+// outcomeError is an error together with the outcome it leads to, so that
+// code that must not exit, such as the server, can return it instead.
+type outcomeError struct {
+	outcome outcome
+	err     error
+}
+
+func (e *outcomeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *outcomeError) Unwrap() error {
+	return e.err
+}
+
+func withOutcome(o outcome, err error) error {
+	return &outcomeError{outcome: o, err: err}
+}
+
+// exitOnError exits with the outcome of the error, or as an internal error.
+func exitOnError(err error) {
+	var oe *outcomeError
+	if errors.As(err, &oe) {
+		exitWithOutcome(oe.outcome, oe.err)
+	}
+	exitWithError(err)
+}
+
+func exitCodeSelection(controlsCollection []*check.Controls) int {
+	gated := failUnder > 0 || failOn != ""
+	if gated && complianceGatesFailed(controlsCollection) {
+		if code := exitCodeFor(outcomeFail); code != 0 {
+			return code
+		}
+		return 1
+	}
+
+	outcomes := resultOutcomes(controlsCollection)
+	// The compliance gates replace the default "any FAIL" rule, so that a
+	// pipeline can accept known failures while the score stays high enough.
+	if len(outcomes) > 0 && outcomes[0] == outcomeFail && !gated {
+		// The waiver expired and warn codes are for runs in which nothing
+		// failed, so a failure with --exit-code 0 still exits with 0.
+		return exitCodeFor(outcomeFail)
+	}
+	for _, o := range outcomes {
+		if o == outcomeFail {
+			continue
+		}
+		if code := exitCodeFor(o); code != 0 {
+			return code
+		}
+	}
+
+	return 0
+}
+
+// resultOutcomes returns the outcomes of the checks, most severe first. Failed
+// checks with an expired waiver, and failures known from the baseline, are not
+// counted as failed.
+func resultOutcomes(controlsCollection []*check.Controls) []outcome {
+	var failed, warned, waiverExpired bool
+	for _, controls := range controlsCollection {
+		var expired int
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				switch {
+				case c.State == check.FAIL && c.Waiver != nil && c.Waiver.Expired:
+					waiverExpired = true
+					if !c.Known {
+						expired++
+					}
+				case c.State == check.WARN:
+					warned = true
+				}
+			}
+		}
+		if controls.Fail > knownFailures(controls)+expired {
+			failed = true
+		}
+	}
+
+	var outcomes []outcome
+	if failed {
+		outcomes = append(outcomes, outcomeFail)
+	}
+	if waiverExpired {
+		outcomes = append(outcomes, outcomeWaiverExpired)
+	}
+	if warned {
+		outcomes = append(outcomes, outcomeWarn)
+	}
+	return outcomes
+}
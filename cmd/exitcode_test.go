@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeSelectionPolicy(t *testing.T) {
+	oldExitCode, oldWarn, oldExpired := exitCode, warnExitCode, waiverExpiredExitCode
+	defer func() {
+		exitCode, warnExitCode, waiverExpiredExitCode = oldExitCode, oldWarn, oldExpired
+	}()
+	exitCode, warnExitCode, waiverExpiredExitCode = 0, 3, 4
+
+	controls := func(checks ...*check.Check) []*check.Controls {
+		c := &check.Controls{Groups: []*check.Group{{ID: "1.1", Checks: checks}}}
+		for _, chk := range checks {
+			if chk.State == check.FAIL {
+				c.Fail++
+			}
+		}
+		return []*check.Controls{c}
+	}
+	expiredWaiver := &check.Waiver{ID: "1.1.2", Expired: true}
+
+	assert.Equal(t, 0, exitCodeSelection(controls(&check.Check{State: check.PASS})))
+	assert.Equal(t, 3, exitCodeSelection(controls(&check.Check{State: check.PASS}, &check.Check{State: check.WARN})))
+	assert.Equal(t, 4, exitCodeSelection(controls(&check.Check{State: check.WARN}, &check.Check{State: check.FAIL, Waiver: expiredWaiver})))
+	assert.Equal(t, 0, exitCodeSelection(controls(&check.Check{State: check.FAIL}, &check.Check{State: check.WAIVED})))
+
+	// A FAIL without a waiver exits with the FAIL exit code, even when it is 0.
+	assert.Equal(t, 0, exitCodeSelection(controls(&check.Check{State: check.FAIL}, &check.Check{State: check.WARN})))
+}
+
+func TestExitCodeSelectionAllCodes(t *testing.T) {
+	oldExitCode, oldWarn, oldExpired := exitCode, warnExitCode, waiverExpiredExitCode
+	defer func() {
+		exitCode, warnExitCode, waiverExpiredExitCode = oldExitCode, oldWarn, oldExpired
+	}()
+	exitCode, waiverExpiredExitCode, warnExitCode = 2, 3, 4
+
+	controls := func(checks ...*check.Check) []*check.Controls {
+		c := &check.Controls{Groups: []*check.Group{{ID: "1.1", Checks: checks}}}
+		for _, chk := range checks {
+			if chk.State == check.FAIL {
+				c.Fail++
+			}
+		}
+		return []*check.Controls{c}
+	}
+	expired := func() *check.Check {
+		return &check.Check{State: check.FAIL, Waiver: &check.Waiver{ID: "1.1.2", Expired: true}}
+	}
+
+	assert.Equal(t, 2, exitCodeSelection(controls(&check.Check{State: check.FAIL}, expired(), &check.Check{State: check.WARN})))
+	assert.Equal(t, 3, exitCodeSelection(controls(expired(), &check.Check{State: check.WARN})))
+	assert.Equal(t, 4, exitCodeSelection(controls(&check.Check{State: check.PASS}, &check.Check{State: check.WARN})))
+	assert.Equal(t, 0, exitCodeSelection(controls(&check.Check{State: check.PASS})))
+}
+
+func TestExitCodeFor(t *testing.T) {
+	defer viper.Reset()
+
+	assert.Equal(t, 1, exitCodeFor(outcomeBenchmarkNotDetected))
+	assert.Equal(t, 1, exitCodeFor(outcomeInternalError))
+
+	viper.Set("exit_codes.benchmark_not_detected", 5)
+	assert.Equal(t, 5, exitCodeFor(outcomeBenchmarkNotDetected))
+	assert.Equal(t, 1, exitCodeFor(outcomeInternalError))
+}
+
+func TestOutcomeError(t *testing.T) {
+	defer func() { configFileError = nil }()
+	configFileError = errors.New("config.yaml not found")
+
+	_, err := loadControls(check.NODE, "./testdata/node.yaml", "")
+	var oe *outcomeError
+	assert.True(t, errors.As(err, &oe), "expected an outcome error, got %v", err)
+	assert.Equal(t, outcomeConfigNotFound, oe.outcome)
+
+	wrapped := fmt.Errorf("scan failed: %w", withOutcome(outcomeBenchmarkNotDetected, errors.New("no version")))
+	assert.True(t, errors.As(wrapped, &oe))
+	assert.Equal(t, outcomeBenchmarkNotDetected, oe.outcome)
+	assert.Equal(t, "scan failed: no version", wrapped.Error())
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+const (
+	filterFieldCheck = "check"
+	filterFieldGroup = "group"
+	filterFieldTag   = "tag"
+	// filterFieldID matches a group or a check ID, for --skip.
+	filterFieldID = "id"
+)
+
+var dottedIDRe = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// filterTerm is a single element of a filter expression, e.g. "1.2.*",
+// "group:4.2", "!4.2.3" or "tag:kubelet".
+type filterTerm struct {
+	field   string
+	pattern string
+	exclude bool
+}
+
+// NewRunFilter constructs a Predicate based on FilterOpts which determines whether tested Checks should be run or not.
+// Groups, checks and the filter expression are combined: a check runs if, for each of the group, check and tag
+// fields that has include terms, it matches one of them, and it matches no exclude term.
+func NewRunFilter(opts FilterOpts) (check.Predicate, error) {
+	includes := make(map[string][]filterTerm)
+	var fields []string
+	var excludes []filterTerm
+	for _, src := range []struct {
+		list  string
+		field string
+	}{
+		{opts.GroupList, filterFieldGroup},
+		{opts.CheckList, filterFieldCheck},
+		{opts.Expression, filterFieldCheck},
+	} {
+		terms, err := parseFilterExpression(src.list, src.field)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range terms {
+			if t.exclude {
+				excludes = append(excludes, t)
+			} else {
+				if _, ok := includes[t.field]; !ok {
+					fields = append(fields, t.field)
+				}
+				includes[t.field] = append(includes[t.field], t)
+			}
+		}
+	}
+
+	return func(g *check.Group, c *check.Check) bool {
+		if !(opts.Scored && c.Scored || opts.Unscored && !c.Scored) {
+			return false
+		}
+
+		for _, t := range excludes {
+			if t.matches(g, c) {
+				return false
+			}
+		}
+
+		for _, field := range fields {
+			if !matchesAny(includes[field], g, c) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// NewSkipFilter constructs a Predicate that reports whether a check is skipped with --skip. Terms
+// without a field prefix match a group or a check ID, so "1.1" skips the checks of group 1.1.
+func NewSkipFilter(skipList string) (check.Predicate, error) {
+	terms, err := parseFilterExpression(skipList, filterFieldID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range terms {
+		if t.exclude {
+			return nil, fmt.Errorf("invalid skip term %q: skipped checks can't be excluded", "!"+t.pattern)
+		}
+	}
+
+	return func(g *check.Group, c *check.Check) bool {
+		return matchesAny(terms, g, c)
+	}, nil
+}
+
+func matchesAny(terms []filterTerm, g *check.Group, c *check.Check) bool {
+	for _, t := range terms {
+		if t.matches(g, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFilterActive reports whether the user restricted the set of checks to run.
+func isFilterActive(opts FilterOpts) bool {
+	return !isEmpty(opts.CheckList) || !isEmpty(opts.GroupList) || !isEmpty(opts.Expression)
+}
+
+// checkFilterMatched returns an error if a filter was given but no check in any of
+// the controls matched it, which usually means the filter has a typo.
+func checkFilterMatched(opts FilterOpts, controlsCollection []*check.Controls) error {
+	if !isFilterActive(opts) {
+		return nil
+	}
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			if len(g.Checks) > 0 {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("the specified filter (check: %q, group: %q, filter: %q) did not match any checks",
+		opts.CheckList, opts.GroupList, opts.Expression)
+}
+
+// parseFilterExpression splits a comma-delimited filter expression into terms.
+// Terms without an explicit "check:", "group:" or "tag:" prefix apply to defaultField.
+func parseFilterExpression(expr string, defaultField string) ([]filterTerm, error) {
+	var terms []filterTerm
+	if isEmpty(expr) {
+		return terms, nil
+	}
+
+	for _, raw := range strings.Split(expr, ",") {
+		s := strings.TrimSpace(raw)
+		if s == "" {
+			continue
+		}
+
+		t := filterTerm{field: defaultField}
+		if strings.HasPrefix(s, "!") {
+			t.exclude = true
+			s = strings.TrimSpace(s[1:])
+		}
+
+		if i := strings.Index(s, ":"); i >= 0 {
+			switch field := strings.ToLower(s[:i]); field {
+			case filterFieldCheck, filterFieldGroup, filterFieldTag:
+				t.field = field
+				s = strings.TrimSpace(s[i+1:])
+			default:
+				return nil, fmt.Errorf("unknown filter field %q in %q", field, raw)
+			}
+		}
+
+		if s == "" {
+			return nil, fmt.Errorf("empty filter term %q", raw)
+		}
+		if lo, hi, ok := splitIDRange(s); ok && compareDottedIDs(lo, hi) > 0 {
+			return nil, fmt.Errorf("invalid filter range %q: %s is after %s", raw, lo, hi)
+		}
+
+		t.pattern = s
+		terms = append(terms, t)
+	}
+
+	return terms, nil
+}
+
+func (t filterTerm) matches(g *check.Group, c *check.Check) bool {
+	switch t.field {
+	case filterFieldGroup:
+		return matchID(t.pattern, g.ID)
+	case filterFieldID:
+		return matchID(t.pattern, g.ID) || matchID(t.pattern, c.ID)
+	case filterFieldTag:
+		for _, tag := range c.Tags {
+			if matchID(t.pattern, tag) {
+				return true
+			}
+		}
+		return false
+	default:
+		return matchID(t.pattern, c.ID)
+	}
+}
+
+// matchID matches an ID against an exact value, a glob ("1.2.*") or an
+// inclusive range of dotted IDs ("4.2.1-4.2.6").
+func matchID(pattern, id string) bool {
+	if lo, hi, ok := splitIDRange(pattern); ok {
+		if !dottedIDRe.MatchString(id) {
+			return false
+		}
+		return compareDottedIDs(lo, id) <= 0 && compareDottedIDs(id, hi) <= 0
+	}
+
+	if strings.ContainsAny(pattern, "*?") {
+		return globToRegexp(pattern).MatchString(id)
+	}
+
+	return pattern == id
+}
+
+// splitIDRange splits "A-B" when both A and B are dotted numeric IDs, so that
+// IDs that contain dashes (e.g. STIG "V-242387") are still matched literally.
+func splitIDRange(pattern string) (string, string, bool) {
+	parts := strings.Split(pattern, "-")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	lo, hi := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if !dottedIDRe.MatchString(lo) || !dottedIDRe.MatchString(hi) {
+		return "", "", false
+	}
+	return lo, hi, true
+}
+
+// compareDottedIDs compares two dotted numeric IDs component by component,
+// so that 1.2.10 sorts after 1.2.9.
+func compareDottedIDs(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, _ := strconv.Atoi(as[i])
+		bi, _ := strconv.Atoi(bs[i])
+		if ai != bi {
+			if ai < bi {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(as) - len(bs)
+}
+
+func globToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRunFilter(t *testing.T) {
+	type TestCase struct {
+		Name       string
+		FilterOpts FilterOpts
+		Group      *check.Group
+		Check      *check.Check
+
+		Expected bool
+	}
+
+	testCases := []TestCase{
+		{
+			Name:       "Should return true when scored flag is enabled and check is scored",
+			FilterOpts: FilterOpts{Scored: true, Unscored: false},
+			Group:      &check.Group{},
+			Check:      &check.Check{Scored: true},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when scored flag is enabled and check is not scored",
+			FilterOpts: FilterOpts{Scored: true, Unscored: false},
+			Group:      &check.Group{},
+			Check:      &check.Check{Scored: false},
+			Expected:   false,
+		},
+
+		{
+			Name:       "Should return true when unscored flag is enabled and check is not scored",
+			FilterOpts: FilterOpts{Scored: false, Unscored: true},
+			Group:      &check.Group{},
+			Check:      &check.Check{Scored: false},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when unscored flag is enabled and check is scored",
+			FilterOpts: FilterOpts{Scored: false, Unscored: true},
+			Group:      &check.Group{},
+			Check:      &check.Check{Scored: true},
+			Expected:   false,
+		},
+
+		{
+			Name:       "Should return true when group flag contains group's ID",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "G1,G2,G3"},
+			Group:      &check.Group{ID: "G2"},
+			Check:      &check.Check{},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when group flag doesn't contain group's ID",
+			FilterOpts: FilterOpts{GroupList: "G1,G3"},
+			Group:      &check.Group{ID: "G2"},
+			Check:      &check.Check{},
+			Expected:   false,
+		},
+
+		{
+			Name:       "Should return true when check flag contains check's ID",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, CheckList: "C1,C2,C3"},
+			Group:      &check.Group{},
+			Check:      &check.Check{ID: "C2"},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when check flag doesn't contain check's ID",
+			FilterOpts: FilterOpts{CheckList: "C1,C3"},
+			Group:      &check.Group{},
+			Check:      &check.Check{ID: "C2"},
+			Expected:   false,
+		},
+
+		{
+			Name:       "Should return true when group and check flags are combined and both match",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "G1", CheckList: "C1,C2"},
+			Group:      &check.Group{ID: "G1"},
+			Check:      &check.Check{ID: "C2"},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when group and check flags are combined and only the group matches",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "G1", CheckList: "C1"},
+			Group:      &check.Group{ID: "G1"},
+			Check:      &check.Check{ID: "C2"},
+			Expected:   false,
+		},
+		{
+			Name:       "Should return false when group and check flags are combined and only the check matches",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "G1", CheckList: "C1"},
+			Group:      &check.Group{ID: "G2"},
+			Check:      &check.Check{ID: "C1"},
+			Expected:   false,
+		},
+		{
+			Name:       "Should return true when check matches a glob",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, CheckList: "1.2.*"},
+			Group:      &check.Group{ID: "1.2"},
+			Check:      &check.Check{ID: "1.2.15"},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return true when check is inside a range",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, CheckList: "4.2.1-4.2.6"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.4"},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when check is outside a range",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, CheckList: "4.2.1-4.2.6"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.10"},
+			Expected:   false,
+		},
+		{
+			Name:       "Should return false when check is excluded from its group",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "4.2", Expression: "!4.2.3"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.3"},
+			Expected:   false,
+		},
+		{
+			Name:       "Should return true when only excludes are given and check is not excluded",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, Expression: "!group:1.*"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.3"},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return true when check has a matching tag",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, Expression: "tag:kubelet"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.3", Tags: []string{"kubelet", "tls"}},
+			Expected:   true,
+		},
+		{
+			Name:       "Should return false when a tag narrows a group and the check doesn't have it",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, GroupList: "4.2", Expression: "tag:kubelet"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.3", Tags: []string{"tls"}},
+			Expected:   false,
+		},
+		{
+			Name:       "Should return true when a check matches one of the terms of a field",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, Expression: "tag:kubelet,tag:tls"},
+			Group:      &check.Group{ID: "4.2"},
+			Check:      &check.Check{ID: "4.2.3", Tags: []string{"tls"}},
+			Expected:   true,
+		},
+		{
+			Name:       "Should match dashed STIG IDs literally",
+			FilterOpts: FilterOpts{Scored: true, Unscored: true, CheckList: "V-242387"},
+			Group:      &check.Group{ID: "3.1"},
+			Check:      &check.Check{ID: "V-242387"},
+			Expected:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			filter, _ := NewRunFilter(testCase.FilterOpts)
+			assert.Equal(t, testCase.Expected, filter(testCase.Group, testCase.Check))
+		})
+	}
+
+	t.Run("Should return error for an unknown filter field", func(t *testing.T) {
+		// given
+		opts := FilterOpts{Expression: "severity:high"}
+		// when
+		_, err := NewRunFilter(opts)
+		// then
+		assert.EqualError(t, err, `unknown filter field "severity" in "severity:high"`)
+	})
+
+	t.Run("Should return error for a reversed range", func(t *testing.T) {
+		_, err := NewRunFilter(FilterOpts{CheckList: "4.2.6-4.2.1"})
+		assert.EqualError(t, err, `invalid filter range "4.2.6-4.2.1": 4.2.6 is after 4.2.1`)
+	})
+}
+
+func TestNewSkipFilter(t *testing.T) {
+	skip, err := NewSkipFilter("1.1, 1.2.*, 4.2.1-4.2.3, tag:etcd")
+	assert.NoError(t, err)
+
+	assert.True(t, skip(&check.Group{ID: "1.1"}, &check.Check{ID: "1.1.7"}), "group ID")
+	assert.True(t, skip(&check.Group{ID: "1.2"}, &check.Check{ID: "1.2.15"}), "glob")
+	assert.True(t, skip(&check.Group{ID: "4.2"}, &check.Check{ID: "4.2.2"}), "range")
+	assert.True(t, skip(&check.Group{ID: "2"}, &check.Check{ID: "2.1", Tags: []string{"etcd"}}), "tag")
+	assert.False(t, skip(&check.Group{ID: "4.2"}, &check.Check{ID: "4.2.4"}))
+	assert.False(t, skip(&check.Group{ID: "1.10"}, &check.Check{ID: "1.10.1"}))
+
+	skip, err = NewSkipFilter("")
+	assert.NoError(t, err)
+	assert.False(t, skip(&check.Group{ID: "1.1"}, &check.Check{ID: "1.1.1"}))
+
+	_, err = NewSkipFilter("!1.1")
+	assert.Error(t, err)
+}
+
+func TestCheckFilterMatched(t *testing.T) {
+	controlsCollection, err := parseControlsJsonFile("./testdata/controlsCollection.json")
+	if err != nil {
+		t.Error(err)
+	}
+	empty := []*check.Controls{{ID: "1", Groups: []*check.Group{}}}
+
+	assert.NoError(t, checkFilterMatched(FilterOpts{}, empty))
+	assert.NoError(t, checkFilterMatched(FilterOpts{CheckList: "1.1.1"}, controlsCollection))
+	assert.EqualError(t, checkFilterMatched(FilterOpts{CheckList: "9.9.9"}, empty),
+		`the specified filter (check: "9.9.9", group: "", filter: "") did not match any checks`)
+}
+
+func TestMatchID(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		id       string
+		expected bool
+	}{
+		{pattern: "1.2.3", id: "1.2.3", expected: true},
+		{pattern: "1.2.3", id: "1.2.30", expected: false},
+		{pattern: "1.2.*", id: "1.2.30", expected: true},
+		{pattern: "1.?", id: "1.2", expected: true},
+		{pattern: "1.2.9-1.2.11", id: "1.2.10", expected: true},
+		{pattern: "1.2.9-1.2.11", id: "1.2", expected: false},
+		{pattern: "1.2.9-1.2.11", id: "V-1", expected: false},
+		{pattern: "V-*", id: "V-242387", expected: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.pattern+" "+c.id, func(t *testing.T) {
+			assert.Equal(t, c.expected, matchID(c.pattern, c.id))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+// reportData is what the rendered reports are built from: the same controls
+// prettyPrint uses, together with the totals, score and scan metadata.
+type reportData struct {
+	Node       string
+	Generated  string
+	Version    string
+	Benchmarks []string
+	Controls   []*check.Controls
+	Totals     check.Summary
+	Score      check.Score
+}
+
+func newReportData(controlsCollection []*check.Controls) reportData {
+	var benchmarks []string
+	seen := make(map[string]bool)
+	for _, controls := range controlsCollection {
+		if controls.Version != "" && !seen[controls.Version] {
+			seen[controls.Version] = true
+			benchmarks = append(benchmarks, controls.Version)
+		}
+	}
+
+	return reportData{
+		Node:       resultNode(),
+		Generated:  time.Now().UTC().Format(time.RFC3339),
+		Version:    resultKubeBenchVersion(),
+		Benchmarks: benchmarks,
+		Controls:   controlsCollection,
+		Totals:     getSummaryTotals(controlsCollection),
+		Score:      check.ComputeScore(controlsCollection, scoreWeights()),
+	}
+}
+
+// renderHTML renders a self-contained HTML report, with its styles and
+// scripts inline so that it can be opened without network access.
+func renderHTML(data reportData) ([]byte, error) {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{"join": strings.Join}).Parse(htmlReportTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeHTMLOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := renderHTML(newReportData(controlsCollection))
+	if err != nil {
+		return fmt.Errorf("failed to output in HTML format: %v", err)
+	}
+	return printOutput(string(out), destination)
+}
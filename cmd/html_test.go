@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHTML(t *testing.T) {
+	controls := []*check.Controls{{
+		ID:      "1",
+		Version: "cis-1.9",
+		Text:    "Control Plane Security Configuration",
+		Type:    check.MASTER,
+		Groups: []*check.Group{{
+			ID:   "1.1",
+			Text: "Control Plane Node Configuration Files",
+			Fail: 1,
+			Pass: 1,
+			Checks: []*check.Check{
+				{ID: "1.1.1", Text: "Ensure <permissions>", State: check.FAIL, Scored: true, ActualValue: "permissions=777", ExpectedResult: "600", Remediation: "chmod 600 file"},
+				{ID: "1.1.2", Text: "Ensure ownership", State: check.PASS, Scored: true},
+			},
+		}},
+		Summary: check.Summary{Pass: 1, Fail: 1},
+	}}
+
+	data := newReportData(controls)
+	data.Node = "master-1"
+	out, err := renderHTML(data)
+	assert.NoError(t, err)
+
+	html := string(out)
+	assert.Contains(t, html, "<title>kube-bench report for master-1</title>")
+	assert.Contains(t, html, "cis-1.9")
+	assert.Contains(t, html, "Ensure &lt;permissions&gt;")
+	assert.Contains(t, html, `<tr data-state="FAIL">`)
+	assert.Contains(t, html, "<pre>permissions=777</pre>")
+	assert.Contains(t, html, "<pre>chmod 600 file</pre>")
+	assert.Contains(t, html, "50.00%")
+	assert.False(t, strings.Contains(html, "src=\"http") || strings.Contains(html, "href=\"http"), "report must not load remote resources")
+}
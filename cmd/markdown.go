@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// markdownCondensedMaxValue is the length at which the actual value of a
+// check is truncated in the condensed Markdown report.
+const markdownCondensedMaxValue = 500
+
+// renderMarkdown renders a GitHub-flavored Markdown report. The condensed
+// report leaves out the tables of checks and shows only the failed checks, so
+// that it fits in pull request comments and CI job summaries.
+func renderMarkdown(data reportData, condensed bool) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# kube-bench report\n\n")
+	fmt.Fprintf(&b, "- **Node:** %s\n", markdownEscape(data.Node))
+	fmt.Fprintf(&b, "- **Generated:** %s\n", data.Generated)
+	if data.Version != "" {
+		fmt.Fprintf(&b, "- **kube-bench version:** %s\n", markdownEscape(data.Version))
+	}
+	fmt.Fprintf(&b, "- **Benchmark:** %s\n", markdownEscape(strings.Join(data.Benchmarks, ", ")))
+	fmt.Fprintf(&b, "- **Compliance score:** %.2f%%\n\n", data.Score.Overall)
+
+	fmt.Fprintf(&b, "## Totals\n\n")
+	fmt.Fprintf(&b, "| Target | PASS | FAIL | WARN | INFO | WAIVED | Score |\n")
+	fmt.Fprintf(&b, "| --- | ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	for _, controls := range data.Controls {
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d | %.2f%% |\n", markdownCell(fmt.Sprintf("%s %s", controls.ID, controls.Text)),
+			controls.Pass, controls.Fail, controls.Warn, controls.Info, controls.Waived, data.Score.Targets[string(controls.Type)])
+	}
+	fmt.Fprintf(&b, "| **Total** | **%d** | **%d** | **%d** | **%d** | **%d** | **%.2f%%** |\n",
+		data.Totals.Pass, data.Totals.Fail, data.Totals.Warn, data.Totals.Info, data.Totals.Waived, data.Score.Overall)
+
+	for _, controls := range data.Controls {
+		if condensed && controls.Fail == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s %s (%s)\n\n", controls.ID, markdownEscape(controls.Text), controls.Type)
+
+		if !condensed {
+			fmt.Fprintf(&b, "| ID | State | Description |\n")
+			fmt.Fprintf(&b, "| --- | --- | --- |\n")
+			for _, g := range controls.Groups {
+				for _, c := range g.Checks {
+					fmt.Fprintf(&b, "| %s | %s | %s |\n", c.ID, c.State, markdownCell(c.Text))
+				}
+			}
+			b.WriteString("\n")
+		}
+
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				if c.State == check.FAIL {
+					writeMarkdownFailure(&b, c, condensed)
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func writeMarkdownFailure(b *strings.Builder, c *check.Check, condensed bool) {
+	fmt.Fprintf(b, "<details>\n<summary><b>FAIL</b> %s %s</summary>\n\n", c.ID, htmlEscaper.Replace(c.Text))
+	if c.Reason != "" {
+		fmt.Fprintf(b, "**Reason:** %s\n\n", markdownEscape(c.Reason))
+	}
+	if c.ExpectedResult != "" {
+		fmt.Fprintf(b, "**Expected:**\n\n%s\n", markdownCodeBlock(c.ExpectedResult))
+	}
+	if c.ActualValue != "" {
+		actual := c.ActualValue
+		if condensed {
+			actual = truncateValue(actual, markdownCondensedMaxValue)
+		}
+		fmt.Fprintf(b, "**Actual:**\n\n%s\n", markdownCodeBlock(actual))
+	}
+	if c.Remediation != "" {
+		fmt.Fprintf(b, "**Remediation:**\n\n%s\n", markdownCodeBlock(c.Remediation))
+	}
+	b.WriteString("</details>\n\n")
+}
+
+var (
+	htmlEscaper     = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	markdownEscaper = strings.NewReplacer("\\", "\\\\", "*", "\\*", "_", "\\_", "`", "\\`", "<", "&lt;", ">", "&gt;", "|", "\\|")
+)
+
+// truncateValue cuts s after max runes, so that a multi-byte character is
+// never split, and marks the cut with "...".
+func truncateValue(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "..."
+}
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownCell escapes text for a table cell, which can't span lines.
+func markdownCell(s string) string {
+	return strings.Join(strings.Fields(markdownEscape(s)), " ")
+}
+
+// markdownCodeBlock fences text with more backticks than any run of backticks it contains.
+func markdownCodeBlock(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s\n%s\n%s\n", fence, strings.TrimRight(s, "\n"), fence)
+}
+
+func writeMarkdownOutput(controlsCollection []*check.Controls, destination string) error {
+	return printOutput(renderMarkdown(newReportData(controlsCollection), markdownCondensed), destination)
+}
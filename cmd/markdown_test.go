@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	controls := []*check.Controls{
+		{
+			ID:      "1",
+			Version: "cis-1.9",
+			Text:    "Control Plane Security Configuration",
+			Type:    check.MASTER,
+			Groups: []*check.Group{{
+				ID: "1.1",
+				Checks: []*check.Check{
+					{ID: "1.1.1", Text: "Ensure <permissions> | mode", State: check.FAIL, ActualValue: strings.Repeat("x", 600), ExpectedResult: "600", Remediation: "Run ```chmod```"},
+					{ID: "1.1.2", Text: "Ensure ownership", State: check.PASS},
+				},
+			}},
+			Summary: check.Summary{Pass: 1, Fail: 1},
+		},
+		{
+			ID:      "4",
+			Version: "cis-1.9",
+			Text:    "Worker Node Security Configuration",
+			Type:    check.NODE,
+			Groups: []*check.Group{{
+				ID:     "4.1",
+				Checks: []*check.Check{{ID: "4.1.1", Text: "Ensure kubelet", State: check.PASS}},
+			}},
+			Summary: check.Summary{Pass: 1},
+		},
+	}
+
+	data := newReportData(controls)
+
+	full := renderMarkdown(data, false)
+	assert.Contains(t, full, "| 1 Control Plane Security Configuration | 1 | 1 | 0 | 0 | 0 | 50.00% |")
+	assert.Contains(t, full, "| **Total** | **2** | **1** |")
+	assert.Contains(t, full, "| 1.1.1 | FAIL | Ensure &lt;permissions&gt; \\| mode |")
+	assert.Contains(t, full, "<summary><b>FAIL</b> 1.1.1 Ensure &lt;permissions&gt; | mode</summary>")
+	assert.Contains(t, full, "````\nRun ```chmod```\n````")
+	assert.Contains(t, full, strings.Repeat("x", 600))
+	assert.Contains(t, full, "## 4 Worker Node Security Configuration (node)")
+
+	condensed := renderMarkdown(data, true)
+	assert.NotContains(t, condensed, "| 1.1.2 | PASS |")
+	assert.NotContains(t, condensed, "## 4 Worker Node Security Configuration")
+	assert.NotContains(t, condensed, strings.Repeat("x", 600))
+	assert.Contains(t, condensed, strings.Repeat("x", markdownCondensedMaxValue)+"...")
+}
+
+func TestTruncateValue(t *testing.T) {
+	assert.Equal(t, "short", truncateValue("short", 10))
+	assert.Equal(t, "abc...", truncateValue("abcdef", 3))
+
+	// Multi-byte characters are kept whole.
+	cut := truncateValue(strings.Repeat("é", 10), 5)
+	assert.Equal(t, strings.Repeat("é", 5)+"...", cut)
+	assert.True(t, utf8.ValidString(cut))
+}
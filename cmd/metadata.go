@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+var (
+	// scanMetadata describes the scan in progress, and is written with its results.
+	scanMetadata *check.Metadata
+	// benchmarkReason is why getBenchmarkVersion chose the benchmark it returned.
+	benchmarkReason string
+)
+
+// startScanMetadata starts the metadata of a scan of the given benchmark. A
+// new scan ID is generated unless one is given.
+func startScanMetadata(scanID, benchmark string, platform Platform) {
+	if scanID == "" {
+		scanID = uuid.NewString()
+	}
+	scanMetadata = &check.Metadata{
+		ScanID:           scanID,
+		Node:             getNodeName(),
+		KubeBenchVersion: KubeBenchVersion,
+		StartTime:        time.Now().UTC(),
+		Benchmark:        benchmark,
+		BenchmarkReason:  benchmarkReason,
+		Platform:         strings.TrimSpace(platform.Name + " " + platform.Version),
+	}
+	if detecetedKubeVersion != "none" {
+		scanMetadata.KubernetesVersion = detecetedKubeVersion
+	}
+	glog.V(2).Infof("Starting scan %s", scanMetadata.ScanID)
+}
+
+// recordComponents adds the components of a target, and the binaries and
+// files found for them, to the metadata of the scan in progress. Files that
+// don't exist on the node are left out, and kept in missingComponentFiles.
+func recordComponents(nodetype check.NodeType, binmap map[string]string, files map[string]map[string]string) {
+	if scanMetadata == nil {
+		return
+	}
+
+	names := make(map[string]bool)
+	for name := range binmap {
+		names[name] = true
+	}
+	for _, filemap := range files {
+		for name := range filemap {
+			names[name] = true
+		}
+	}
+
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	found := func(kind, name string) string {
+		path := files[kind][name]
+		if path == "" {
+			return ""
+		}
+		if _, err := statFunc(path); err != nil {
+			// Without a config file, the path is the name of the component.
+			if filepath.IsAbs(path) {
+				missingComponentFiles[path] = true
+			}
+			return ""
+		}
+		delete(missingComponentFiles, path)
+		return path
+	}
+	for _, name := range sorted {
+		scanMetadata.Components = append(scanMetadata.Components, check.Component{
+			Target:     nodetype,
+			Name:       name,
+			Binary:     binmap[name],
+			Config:     found("config", name),
+			Service:    found("service", name),
+			Kubeconfig: found("kubeconfig", name),
+			CAFile:     found("ca", name),
+			DataDir:    found("datadir", name),
+		})
+	}
+}
+
+// finishScanMetadata records the end of the scan in progress.
+func finishScanMetadata() {
+	if scanMetadata != nil {
+		scanMetadata.Finish(time.Now().UTC())
+	}
+}
+
+// resultNode returns the node the results being written come from: that of
+// the scan metadata, which is not this node when saved results are rendered
+// again, or else this node.
+func resultNode() string {
+	if scanMetadata != nil && scanMetadata.Node != "" {
+		return scanMetadata.Node
+	}
+	return getNodeName()
+}
+
+// resultKubeBenchVersion returns the version of kube-bench that produced the
+// results being written.
+func resultKubeBenchVersion() string {
+	if scanMetadata != nil && scanMetadata.KubeBenchVersion != "" {
+		return scanMetadata.KubeBenchVersion
+	}
+	return KubeBenchVersion
+}
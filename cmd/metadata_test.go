@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+)
+
+func TestGetBenchmarkVersionReason(t *testing.T) {
+	v, err := loadConfigForTest()
+	if err != nil {
+		t.Fatalf("Unable to load config file %v", err)
+	}
+
+	cases := []struct {
+		name             string
+		kubeVersion      string
+		benchmarkVersion string
+		exp              string
+	}{
+		{name: "benchmark flag", benchmarkVersion: "cis-1.5", exp: "set with --benchmark"},
+		{name: "version flag", kubeVersion: "1.15", exp: "mapped from Kubernetes version 1.15 set with --version"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := getBenchmarkVersion(c.kubeVersion, c.benchmarkVersion, Platform{}, v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if benchmarkReason != c.exp {
+				t.Errorf("expected reason %q, got %q", c.exp, benchmarkReason)
+			}
+		})
+	}
+}
+
+func TestStartScanMetadata(t *testing.T) {
+	defer func() { scanMetadata = nil }()
+	viper.Set("NODE_NAME", "node-1")
+	defer viper.Set("NODE_NAME", "")
+	detecetedKubeVersion = "1.27"
+	defer func() { detecetedKubeVersion = "" }()
+	benchmarkReason = "mapped from detected Kubernetes version 1.27"
+
+	startScanMetadata("", "cis-1.8", Platform{Name: "eks", Version: "1.27"})
+	first := scanMetadata.ScanID
+	if first == "" {
+		t.Fatal("expected a scan ID")
+	}
+	if scanMetadata.Node != "node-1" || scanMetadata.Benchmark != "cis-1.8" || scanMetadata.Platform != "eks 1.27" {
+		t.Errorf("unexpected metadata %+v", scanMetadata)
+	}
+	if scanMetadata.KubernetesVersion != "1.27" || scanMetadata.BenchmarkReason != benchmarkReason {
+		t.Errorf("unexpected metadata %+v", scanMetadata)
+	}
+
+	startScanMetadata("", "cis-1.8", Platform{})
+	if scanMetadata.ScanID == first {
+		t.Errorf("expected a new scan ID for each scan")
+	}
+	if scanMetadata.Platform != "" {
+		t.Errorf("expected no platform, got %q", scanMetadata.Platform)
+	}
+
+	startScanMetadata("id-1", "cis-1.8", Platform{})
+	if scanMetadata.ScanID != "id-1" {
+		t.Errorf("expected the given scan ID, got %q", scanMetadata.ScanID)
+	}
+}
+
+func TestRecordComponents(t *testing.T) {
+	defer func() { scanMetadata, missingComponentFiles = nil, make(map[string]bool) }()
+	missingComponentFiles = make(map[string]bool)
+	restore := statFunc
+	defer func() { statFunc = restore }()
+	statFunc = os.Stat
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "kubelet.conf")
+	if err := os.WriteFile(conf, []byte{}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scanMetadata = &check.Metadata{}
+	recordComponents(check.NODE,
+		map[string]string{"kubelet": "kubelet", "proxy": "kube-proxy"},
+		map[string]map[string]string{
+			"config":     {"kubelet": conf, "proxy": filepath.Join(dir, "missing.conf")},
+			"kubeconfig": {"kubelet": filepath.Join(dir, "missing.kubeconfig")},
+		})
+
+	exp := []check.Component{
+		{Target: check.NODE, Name: "kubelet", Binary: "kubelet", Config: conf},
+		{Target: check.NODE, Name: "proxy", Binary: "kube-proxy"},
+	}
+	if len(scanMetadata.Components) != len(exp) {
+		t.Fatalf("expected %d components, got %+v", len(exp), scanMetadata.Components)
+	}
+	for i := range exp {
+		if scanMetadata.Components[i] != exp[i] {
+			t.Errorf("expected %+v, got %+v", exp[i], scanMetadata.Components[i])
+		}
+	}
+
+	expMissing := map[string]bool{
+		filepath.Join(dir, "missing.conf"):       true,
+		filepath.Join(dir, "missing.kubeconfig"): true,
+	}
+	if !reflect.DeepEqual(missingComponentFiles, expMissing) {
+		t.Errorf("expected missing files %v, got %v", expMissing, missingComponentFiles)
+	}
+}
+
+func TestWriteJSONOutputMetadata(t *testing.T) {
+	defer func() { scanMetadata = nil }()
+	scanMetadata = &check.Metadata{ScanID: "id-1", Node: "node-1", Benchmark: "cis-1.8", StartTime: time.Now().Add(-2 * time.Second)}
+	finishScanMetadata()
+	if scanMetadata.DurationSeconds < 2 {
+		t.Errorf("expected a duration of at least 2s, got %v", scanMetadata.DurationSeconds)
+	}
+
+	dest := filepath.Join(t.TempDir(), "result.json")
+	if err := writeJSONOutput([]*check.Controls{{ID: "4", Version: "cis-1.8", Type: check.NODE}}, dest); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result check.OverallControls
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Metadata == nil || result.Metadata.ScanID != "id-1" || result.Metadata.Node != "node-1" {
+		t.Errorf("expected the scan metadata in the output, got %s", out)
+	}
+}
+
+func TestWriteJunitOutputMetadata(t *testing.T) {
+	defer func() { scanMetadata = nil }()
+	scanMetadata = &check.Metadata{ScanID: "id-1", Node: "node-1", Benchmark: "cis-1.8"}
+
+	dest := filepath.Join(t.TempDir(), "result.xml")
+	if err := writeJunitOutput([]*check.Controls{{ID: "4", Text: "Worker Node", Version: "cis-1.8", Type: check.NODE}}, dest); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<property name="scan_id" value="id-1">`, `<property name="node" value="node-1">`, `<property name="benchmark" value="cis-1.8">`} {
+		if !strings.Contains(string(out), s) {
+			t.Errorf("expected %s in:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+)
+
+// getClusterName returns the cluster name from the CLUSTER_NAME setting,
+// falling back to the CLUSTER_ARN used for AWS Security Hub.
+func getClusterName() string {
+	if name := viper.GetString("CLUSTER_NAME"); name != "" {
+		return name
+	}
+	return viper.GetString("CLUSTER_ARN")
+}
+
+// renderOCSF writes one OCSF Compliance Finding per line (NDJSON).
+func renderOCSF(controlsCollection []*check.Controls, scan check.OCSFScan) (string, error) {
+	var lines []string
+	for _, controls := range controlsCollection {
+		for _, f := range controls.OCSF(scan) {
+			out, err := json.Marshal(f)
+			if err != nil {
+				return "", err
+			}
+			lines = append(lines, string(out))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func writeOCSFOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := renderOCSF(controlsCollection, check.OCSFScan{
+		ProductVersion: resultKubeBenchVersion(),
+		Node:           resultNode(),
+		Cluster:        getClusterName(),
+		Time:           time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to output in OCSF format: %v", err)
+	}
+	return printOutput(out, destination)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+func writeOSCALOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := check.OSCAL(controlsCollection, resultNode(), resultKubeBenchVersion(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to output in OSCAL format: %v", err)
+	}
+	return printOutput(string(out), destination)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+)
+
+// outputWriter writes the results of a run to a destination. An empty
+// destination means stdout; writers that send results elsewhere ignore it.
+type outputWriter func(controlsCollection []*check.Controls, destination string) error
+
+var outputWriters = map[string]outputWriter{
+	"text":       writeTextOutput,
+	"json":       writeJSONOutput,
+	"junit":      writeJunitOutput,
+	"sarif":      writeSARIFOutput,
+	"html":       writeHTMLOutput,
+	"markdown":   writeMarkdownOutput,
+	"csv":        writeCSVOutput,
+	"ocsf":       writeOCSFOutput,
+	"xccdf":      writeXCCDFOutput,
+	"arf":        writeARFOutput,
+	"ckl":        writeCKLOutput,
+	"cklb":       writeCKLBOutput,
+	"oscal":      writeOSCALOutput,
+	"template":   writeTemplateOutput,
+	"prometheus": writePrometheusOutput,
+	"pgsql":      writePgsqlOutput,
+	"asff":       writeASFFOutput,
+	"drift":      writeDriftOutput,
+}
+
+// outputSink is a writer together with where it writes to and whether its
+// failure should fail the run.
+type outputSink struct {
+	Format      string `mapstructure:"format"`
+	Destination string `mapstructure:"destination"`
+	Optional    bool   `mapstructure:"optional"`
+}
+
+// getOutputSinks returns the outputs requested with --output or, failing that,
+// in the outputs section of the config file. Without either, the legacy format
+// flags select a single output.
+func getOutputSinks() ([]outputSink, error) {
+	var sinks []outputSink
+	for _, spec := range outputSpecs {
+		s, err := parseOutputSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	if len(sinks) == 0 && viper.IsSet("outputs") {
+		if err := viper.UnmarshalKey("outputs", &sinks); err != nil {
+			return nil, fmt.Errorf("invalid outputs in config file: %v", err)
+		}
+	}
+
+	if len(sinks) == 0 {
+		// The events already go to stdout, so --stream replaces the default text output.
+		if s := legacyOutputSink(); !streamFmt || s.Format != "text" {
+			sinks = append(sinks, s)
+		}
+	}
+
+	for _, s := range sinks {
+		if _, ok := outputWriters[s.Format]; !ok {
+			return nil, fmt.Errorf("unknown output format %q, supported formats are %s", s.Format, strings.Join(outputFormats(), ", "))
+		}
+	}
+	return sinks, nil
+}
+
+// parseOutputSpec parses a "format[=destination][,optional]" output flag value.
+func parseOutputSpec(spec string) (outputSink, error) {
+	var s outputSink
+	if rest, ok := strings.CutSuffix(spec, ",optional"); ok {
+		s.Optional = true
+		spec = rest
+	}
+
+	format, destination, _ := strings.Cut(spec, "=")
+	s.Format = strings.ToLower(strings.TrimSpace(format))
+	s.Destination = strings.TrimSpace(destination)
+	if s.Format == "" {
+		return s, fmt.Errorf("invalid output %q, expected format[=destination][,optional]", spec)
+	}
+	return s, nil
+}
+
+// legacyOutputSink selects a single output from the format flags, such as
+// --junit, --json and --asff, in their order of precedence.
+func legacyOutputSink() outputSink {
+	switch {
+	case junitFmt:
+		return outputSink{Format: "junit", Destination: outputFile}
+	case jsonFmt:
+		return outputSink{Format: "json", Destination: outputFile}
+	case sarifFmt:
+		return outputSink{Format: "sarif", Destination: outputFile}
+	case htmlFmt:
+		return outputSink{Format: "html", Destination: outputFile}
+	case markdownFmt:
+		return outputSink{Format: "markdown", Destination: outputFile}
+	case csvFmt:
+		return outputSink{Format: "csv", Destination: outputFile}
+	case ocsfFmt:
+		return outputSink{Format: "ocsf", Destination: outputFile}
+	case xccdfFmt:
+		return outputSink{Format: "xccdf", Destination: outputFile}
+	case arfFmt:
+		return outputSink{Format: "arf", Destination: outputFile}
+	case cklFmt:
+		return outputSink{Format: "ckl", Destination: outputFile}
+	case cklbFmt:
+		return outputSink{Format: "cklb", Destination: outputFile}
+	case oscalFmt:
+		return outputSink{Format: "oscal", Destination: outputFile}
+	case outputTemplate != "":
+		return outputSink{Format: "template", Destination: outputFile}
+	case prometheusFmt:
+		return outputSink{Format: "prometheus", Destination: outputFile}
+	case pgSQL:
+		return outputSink{Format: "pgsql"}
+	case aSFF:
+		return outputSink{Format: "asff"}
+	default:
+		return outputSink{Format: "text"}
+	}
+}
+
+func outputFormats() []string {
+	formats := make([]string, 0, len(outputWriters))
+	for f := range outputWriters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// writeOutputs writes the results to every sink. A failing sink does not stop
+// the others; the failures of non-optional sinks are returned together.
+func writeOutputs(controlsCollection []*check.Controls, sinks []outputSink) error {
+	sortControls(controlsCollection)
+
+	var failed []string
+	for _, s := range sinks {
+		glog.V(2).Infof("Writing %s output to %q", s.Format, s.Destination)
+		err := outputWriters[s.Format](controlsCollection, s.Destination)
+		if err == nil {
+			continue
+		}
+		if s.Optional {
+			glog.Warningf("failed to write optional %s output: %v", s.Format, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "failed to write %s output: %v\n", s.Format, err)
+		failed = append(failed, s.Format)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to write %s output", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// sortControls sorts the controls by their numeric IDs, i.e. in the order of the benchmark.
+func sortControls(controlsCollection []*check.Controls) {
+	sort.Slice(controlsCollection, func(i, j int) bool {
+		iid, _ := strconv.Atoi(controlsCollection[i].ID)
+		jid, _ := strconv.Atoi(controlsCollection[j].ID)
+		return iid < jid
+	})
+}
+
+// writeTextOutput prints the human-readable results, which always go to stdout.
+func writeTextOutput(controlsCollection []*check.Controls, destination string) error {
+	if destination != "" && destination != "-" {
+		return fmt.Errorf("text output can only be written to stdout")
+	}
+	writeStdoutOutput(controlsCollection)
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutputSpec(t *testing.T) {
+	cases := []struct {
+		spec     string
+		expected outputSink
+		fail     bool
+	}{
+		{spec: "text", expected: outputSink{Format: "text"}},
+		{spec: "JSON=/tmp/results.json", expected: outputSink{Format: "json", Destination: "/tmp/results.json"}},
+		{spec: "asff,optional", expected: outputSink{Format: "asff", Optional: true}},
+		{spec: "junit=report.xml,optional", expected: outputSink{Format: "junit", Destination: "report.xml", Optional: true}},
+		{spec: "=report.xml", fail: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			s, err := parseOutputSpec(c.spec)
+			if c.fail {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, s)
+		})
+	}
+}
+
+func TestGetOutputSinks(t *testing.T) {
+	defer func() {
+		outputSpecs = nil
+		jsonFmt = false
+		sarifFmt = false
+		outputFile = ""
+		viper.Set("outputs", nil)
+	}()
+
+	sinks, err := getOutputSinks()
+	assert.NoError(t, err)
+	assert.Equal(t, []outputSink{{Format: "text"}}, sinks)
+
+	sarifFmt = true
+	outputFile = "out.sarif"
+	sinks, err = getOutputSinks()
+	assert.NoError(t, err)
+	assert.Equal(t, []outputSink{{Format: "sarif", Destination: "out.sarif"}}, sinks)
+
+	jsonFmt = true
+	outputFile = "out.json"
+	sinks, err = getOutputSinks()
+	assert.NoError(t, err)
+	assert.Equal(t, []outputSink{{Format: "json", Destination: "out.json"}}, sinks)
+
+	viper.Set("outputs", []map[string]interface{}{
+		{"format": "json", "destination": "results.json"},
+		{"format": "asff", "optional": true},
+	})
+	sinks, err = getOutputSinks()
+	assert.NoError(t, err)
+	assert.Equal(t, []outputSink{{Format: "json", Destination: "results.json"}, {Format: "asff", Optional: true}}, sinks)
+
+	outputSpecs = []string{"text", "junit=report.xml"}
+	sinks, err = getOutputSinks()
+	assert.NoError(t, err)
+	assert.Equal(t, []outputSink{{Format: "text"}, {Format: "junit", Destination: "report.xml"}}, sinks)
+
+	outputSpecs = []string{"yaml"}
+	_, err = getOutputSinks()
+	assert.Error(t, err)
+}
+
+func TestWriteOutputs(t *testing.T) {
+	controlsCollection, err := parseControlsJsonFile("./testdata/controlsCollection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputWriters["broken"] = func([]*check.Controls, string) error { return errors.New("unreachable") }
+	defer delete(outputWriters, "broken")
+
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "results.json")
+	junitFile := filepath.Join(dir, "results.xml")
+
+	err = writeOutputs(controlsCollection, []outputSink{
+		{Format: "broken", Optional: true},
+		{Format: "json", Destination: jsonFile},
+		{Format: "junit", Destination: junitFile},
+	})
+	assert.NoError(t, err)
+	assert.FileExists(t, jsonFile)
+	assert.FileExists(t, junitFile)
+
+	err = writeOutputs(controlsCollection, []outputSink{
+		{Format: "broken"},
+		{Format: "json", Destination: jsonFile},
+	})
+	assert.EqualError(t, err, "failed to write broken output")
+
+	_, err = os.Stat(jsonFile)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// prometheusStates are the check states in the order their series are written.
+var prometheusStates = []check.State{check.PASS, check.FAIL, check.WARN, check.INFO, check.WAIVED}
+
+// prometheusStatus is the value of the kube_bench_check_status gauge for each state.
+var prometheusStatus = map[check.State]int{
+	check.PASS:   0,
+	check.FAIL:   1,
+	check.WARN:   2,
+	check.INFO:   3,
+	check.WAIVED: 4,
+}
+
+// prometheusMetrics writes metric families in the Prometheus text exposition format.
+type prometheusMetrics struct {
+	b bytes.Buffer
+}
+
+func (m *prometheusMetrics) family(name, typ, help string) {
+	fmt.Fprintf(&m.b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes a sample with labels given as name, value pairs.
+func (m *prometheusMetrics) sample(name string, value float64, labels ...string) {
+	m.b.WriteString(name)
+	if len(labels) > 0 {
+		m.b.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.b.WriteString(",")
+			}
+			fmt.Fprintf(&m.b, "%s=\"%s\"", labels[i], prometheusLabelEscaper.Replace(labels[i+1]))
+		}
+		m.b.WriteString("}")
+	}
+	fmt.Fprintf(&m.b, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func summaryCount(s check.Summary, state check.State) int {
+	switch state {
+	case check.PASS:
+		return s.Pass
+	case check.FAIL:
+		return s.Fail
+	case check.WARN:
+		return s.Warn
+	case check.INFO:
+		return s.Info
+	case check.WAIVED:
+		return s.Waived
+	}
+	return 0
+}
+
+func groupCount(g *check.Group, state check.State) int {
+	return summaryCount(check.Summary{Pass: g.Pass, Fail: g.Fail, Warn: g.Warn, Info: g.Info, Waived: g.Waived}, state)
+}
+
+// renderPrometheus returns the results as Prometheus metrics. The scan
+// duration is only written when the metadata of the scan is known.
+func renderPrometheus(controlsCollection []*check.Controls, metadata *check.Metadata, now time.Time) []byte {
+	var m prometheusMetrics
+
+	if metadata != nil {
+		m.family("kube_bench_info", "gauge", "Information about the kube-bench scan.")
+		m.sample("kube_bench_info", 1,
+			"version", metadata.KubeBenchVersion,
+			"node", metadata.Node,
+			"benchmark", metadata.Benchmark,
+			"scan_id", metadata.ScanID)
+	}
+
+	var benchmarks []string
+	totals := make(map[string]check.Summary)
+	for _, controls := range controlsCollection {
+		if _, ok := totals[controls.Version]; !ok {
+			benchmarks = append(benchmarks, controls.Version)
+		}
+		t := totals[controls.Version]
+		t.Pass += controls.Pass
+		t.Fail += controls.Fail
+		t.Warn += controls.Warn
+		t.Info += controls.Info
+		t.Waived += controls.Waived
+		totals[controls.Version] = t
+	}
+
+	m.family("kube_bench_checks", "gauge", "Number of checks in each state.")
+	for _, b := range benchmarks {
+		for _, state := range prometheusStates {
+			m.sample("kube_bench_checks", float64(summaryCount(totals[b], state)),
+				"benchmark", b, "state", string(state))
+		}
+	}
+
+	m.family("kube_bench_target_checks", "gauge", "Number of checks of a target in each state.")
+	for _, controls := range controlsCollection {
+		for _, state := range prometheusStates {
+			m.sample("kube_bench_target_checks", float64(summaryCount(controls.Summary, state)),
+				"benchmark", controls.Version, "target", string(controls.Type), "state", string(state))
+		}
+	}
+
+	m.family("kube_bench_group_checks", "gauge", "Number of checks of a group in each state.")
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, state := range prometheusStates {
+				m.sample("kube_bench_group_checks", float64(groupCount(g, state)),
+					"benchmark", controls.Version, "target", string(controls.Type), "group", g.ID, "state", string(state))
+			}
+		}
+	}
+
+	m.family("kube_bench_check_status", "gauge", "Status of a check: 0 PASS, 1 FAIL, 2 WARN, 3 INFO, 4 WAIVED.")
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				status, ok := prometheusStatus[c.State]
+				if !ok {
+					continue
+				}
+				m.sample("kube_bench_check_status", float64(status),
+					"benchmark", controls.Version, "target", string(controls.Type), "check", c.ID, "scored", strconv.FormatBool(c.Scored))
+			}
+		}
+	}
+
+	score := check.ComputeScore(controlsCollection, scoreWeights())
+	m.family("kube_bench_compliance_score", "gauge", "Percentage of the evaluated checks, PASS or FAIL, that passed, weighted by score_weights if set.")
+	m.sample("kube_bench_compliance_score", score.Overall)
+
+	if metadata != nil {
+		m.family("kube_bench_scan_duration_seconds", "gauge", "How long the scan took.")
+		m.sample("kube_bench_scan_duration_seconds", metadata.DurationSeconds)
+	}
+
+	m.family("kube_bench_scan_timestamp_seconds", "gauge", "When the scan finished, in seconds since the epoch.")
+	m.sample("kube_bench_scan_timestamp_seconds", float64(now.Unix()))
+
+	return m.b.Bytes()
+}
+
+// writeFileAtomically writes a file through a temporary file in the same
+// directory, so that readers such as the node_exporter textfile collector
+// never see a partly written file.
+func writeFileAtomically(path string, data []byte) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	// The collector only reads *.prom files, so it skips the temporary file.
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func writePrometheusOutput(controlsCollection []*check.Controls, destination string) error {
+	now := time.Now()
+	if scanMetadata != nil && !scanMetadata.EndTime.IsZero() {
+		now = scanMetadata.EndTime
+	}
+	out := renderPrometheus(controlsCollection, scanMetadata, now)
+	if destination == "" || destination == "-" {
+		return printOutput(strings.TrimSuffix(string(out), "\n"), destination)
+	}
+	if err := writeFileAtomically(destination, out); err != nil {
+		return fmt.Errorf("failed to write Prometheus metrics to %s: %v", destination, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func prometheusTestControls() []*check.Controls {
+	return []*check.Controls{{
+		ID:      "4",
+		Version: "cis-1.9",
+		Type:    check.NODE,
+		Groups: []*check.Group{{
+			ID:   "4.1",
+			Pass: 1,
+			Fail: 1,
+			Checks: []*check.Check{
+				{ID: "4.1.1", Scored: true, State: check.PASS},
+				{ID: "4.1.2", Scored: false, State: check.FAIL},
+			},
+		}},
+		Summary: check.Summary{Pass: 1, Fail: 1},
+	}}
+}
+
+func TestRenderPrometheus(t *testing.T) {
+	metadata := &check.Metadata{ScanID: "id-1", Node: "node-1", KubeBenchVersion: "0.10.0", Benchmark: "cis-1.9", DurationSeconds: 2.5}
+	out := string(renderPrometheus(prometheusTestControls(), metadata, time.Unix(1700000000, 0)))
+
+	for _, line := range []string{
+		"# TYPE kube_bench_checks gauge",
+		`kube_bench_info{version="0.10.0",node="node-1",benchmark="cis-1.9",scan_id="id-1"} 1`,
+		`kube_bench_checks{benchmark="cis-1.9",state="PASS"} 1`,
+		`kube_bench_checks{benchmark="cis-1.9",state="WAIVED"} 0`,
+		`kube_bench_target_checks{benchmark="cis-1.9",target="node",state="FAIL"} 1`,
+		`kube_bench_group_checks{benchmark="cis-1.9",target="node",group="4.1",state="PASS"} 1`,
+		`kube_bench_check_status{benchmark="cis-1.9",target="node",check="4.1.1",scored="true"} 0`,
+		`kube_bench_check_status{benchmark="cis-1.9",target="node",check="4.1.2",scored="false"} 1`,
+		// The unscored FAIL counts towards the score like any evaluated check.
+		"kube_bench_compliance_score 50",
+		"kube_bench_scan_duration_seconds 2.5",
+		"kube_bench_scan_timestamp_seconds 1.7e+09",
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+
+	// Every sample must follow the HELP and TYPE of its family.
+	var family string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			family = strings.Fields(line)[2]
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.FieldsFunc(line, func(r rune) bool { return r == '{' || r == ' ' })[0]
+		assert.Equal(t, family, name, "sample %q outside its family", line)
+	}
+
+	out = string(renderPrometheus(prometheusTestControls(), nil, time.Unix(1700000000, 0)))
+	assert.NotContains(t, out, "kube_bench_info")
+	assert.NotContains(t, out, "kube_bench_scan_duration_seconds")
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	var m prometheusMetrics
+	m.sample("metric", 1, "label", "a \"quoted\"\\path\nline")
+	assert.Equal(t, `metric{label="a \"quoted\"\\path\nline"} 1`+"\n", m.b.String())
+}
+
+func TestWritePrometheusOutput(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "kube_bench.prom")
+	assert.NoError(t, os.WriteFile(dest, []byte("old"), 0o644))
+
+	assert.NoError(t, writePrometheusOutput(prometheusTestControls(), dest))
+
+	out, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "kube_bench_check_status")
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file should be renamed over the destination")
+
+	info, err := os.Stat(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	assert.Error(t, writePrometheusOutput(prometheusTestControls(), filepath.Join(dir, "missing", "kube_bench.prom")))
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportInput  string
+	reportFormat string
+)
+
+func init() {
+	RootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportInput, "input", "", "JSON results saved with --json, with or without --nototals")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "text", "Output format, e.g. text, html, markdown, junit or sarif")
+}
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Renders saved JSON results in any output format.",
+	Long: `Reads JSON results saved with --json, for example on the nodes that were
+scanned, and writes them in any output format to stdout or to --outputfile,
+without scanning again or needing the config directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if reportInput == "" {
+			exitWithError(fmt.Errorf("the --input flag is required to know which results to render"))
+		}
+		if err := renderReport(reportInput, reportFormat, outputFile); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// renderReport writes saved results in a format. The metadata of the scan
+// they come from, if saved with them, is used in place of this node's.
+func renderReport(input, format, destination string) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	writer, ok := outputWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown output format %q, supported formats are %s", format, strings.Join(outputFormats(), ", "))
+	}
+
+	results, err := readResults(input)
+	if err != nil {
+		return err
+	}
+	scanMetadata = results.Metadata
+
+	sortControls(results.Controls)
+	return writer(results.Controls, destination)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderReport(t *testing.T) {
+	defer func() { scanMetadata = nil }()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "results.json")
+	saved, err := json.Marshal(&check.OverallControls{
+		SchemaVersion: check.SchemaVersion,
+		Metadata:      &check.Metadata{ScanID: "scan-1", Node: "scanned-node", KubeBenchVersion: "0.9.0", Benchmark: "cis-1.9"},
+		Controls:      prometheusTestControls(),
+		Totals:        check.Summary{Pass: 1, Fail: 1},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(input, saved, 0o600))
+
+	cases := []struct {
+		format   string
+		contains []string
+	}{
+		{format: "html", contains: []string{"scanned-node", "0.9.0", "4.1.2"}},
+		{format: "markdown", contains: []string{"- **Node:** scanned-node", "| 4.1.1 | PASS |"}},
+		{format: "junit", contains: []string{`name="scan_id" value="scan-1"`, "4.1.2"}},
+		{format: "SARIF", contains: []string{`"version": "0.9.0"`, `"ruleId": "4.1.2"`}},
+		{format: "csv", contains: []string{"scanned-node"}},
+		{format: "json", contains: []string{`"scan_id":"scan-1"`, `"schema_version":"` + check.SchemaVersion + `"`}},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			out := filepath.Join(dir, "report."+c.format)
+			require.NoError(t, renderReport(input, c.format, out))
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+			for _, s := range c.contains {
+				assert.Contains(t, string(data), s)
+			}
+		})
+	}
+
+	assert.ErrorContains(t, renderReport(input, "pdf", ""), `unknown output format "pdf"`)
+	assert.Error(t, renderReport(filepath.Join(dir, "missing.json"), "html", ""))
+}
+
+func TestRenderReportText(t *testing.T) {
+	defer func() { scanMetadata = nil }()
+
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	noTotals = false
+	err := renderReport("./testdata/result_no_totals.json", "text", "")
+	w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "1.1.1 Ensure that the API server pod specification file permissions")
+	assert.Contains(t, string(out), "49 checks PASS")
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// readResults reads JSON results saved by writeJSONOutput, with their totals
+// or, with --nototals, as the list of controls only. The totals of a list of
+// controls are counted from them.
+func readResults(path string) (*check.OverallControls, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var controlsCollection []*check.Controls
+		if err := json.Unmarshal(data, &controlsCollection); err != nil {
+			return nil, fmt.Errorf("failed to parse results %s: %v", path, err)
+		}
+		return &check.OverallControls{Controls: controlsCollection, Totals: getSummaryTotals(controlsCollection)}, nil
+	}
+
+	var results check.OverallControls
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse results %s: %v", path, err)
+	}
+	if len(results.Controls) == 0 {
+		return nil, fmt.Errorf("no results in %s", path)
+	}
+	return &results, nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadResults(t *testing.T) {
+	withTotals, err := readResults("./testdata/result.json")
+	require.NoError(t, err)
+	noTotals, err := readResults("./testdata/result_no_totals.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, check.Summary{Pass: 49, Fail: 12, Warn: 14}, withTotals.Totals)
+	assert.Equal(t, withTotals.Totals, noTotals.Totals, "the totals of a list of controls are counted")
+	assert.Equal(t, withTotals.Controls, noTotals.Controls)
+	assert.Equal(t, "1.1.1", noTotals.Controls[0].Groups[0].Checks[0].ID)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"invalid.json": "{",
+		"empty.json":   "{}",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		_, err := readResults(path)
+		assert.Error(t, err, name)
+	}
+	_, err = readResults(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -26,10 +26,11 @@ import (
 )
 
 type FilterOpts struct {
-	CheckList string
-	GroupList string
-	Scored    bool
-	Unscored  bool
+	CheckList  string
+	GroupList  string
+	Expression string
+	Scored     bool
+	Unscored   bool
 }
 
 var (
@@ -42,6 +43,20 @@ var (
 	cfgDir               = "./cfg/"
 	jsonFmt              bool
 	junitFmt             bool
+	sarifFmt             bool
+	htmlFmt              bool
+	markdownFmt          bool
+	markdownCondensed    bool
+	csvFmt               bool
+	ocsfFmt              bool
+	xccdfFmt             bool
+	arfFmt               bool
+	cklFmt               bool
+	cklbFmt              bool
+	oscalFmt             bool
+	outputTemplate       string
+	streamFmt            bool
+	prometheusFmt        bool
 	pgSQL                bool
 	aSFF                 bool
 	masterFile           = "master.yaml"
@@ -51,14 +66,21 @@ var (
 	policiesFile         = "policies.yaml"
 	managedservicesFile  = "managedservices.yaml"
 	exitCode             int
+	failUnder            float64
+	failOn               string
 	noResults            bool
 	noSummary            bool
 	noRemediations       bool
 	skipIds              string
+	waiversFile          string
+	attestationsFile     string
+	baselineFile         string
+	updateBaseline       bool
 	noTotals             bool
 	filterOpts           FilterOpts
 	includeTestOutput    bool
 	outputFile           string
+	outputSpecs          []string
 	configFileError      error
 	controlsCollection   []*check.Controls
 )
@@ -69,70 +91,8 @@ var RootCmd = &cobra.Command{
 	Short: "Run CIS Benchmarks checks against a Kubernetes deployment",
 	Long:  `This tool runs the CIS Kubernetes Benchmark (https://www.cisecurity.org/benchmark/kubernetes/)`,
 	Run: func(cmd *cobra.Command, args []string) {
-		bv, err := getBenchmarkVersion(kubeVersion, benchmarkVersion, getPlatformInfo(), viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("unable to determine benchmark version: %v", err))
-		}
-		glog.V(1).Infof("Running checks for benchmark %v", bv)
-
-		if isMaster() {
-			glog.V(1).Info("== Running master checks ==")
-			runChecks(check.MASTER, loadConfig(check.MASTER, bv), detecetedKubeVersion)
-
-			// Control Plane is only valid for CIS 1.5 and later,
-			// this a gatekeeper for previous versions
-			valid, err := validTargets(bv, []string{string(check.CONTROLPLANE)}, viper.GetViper())
-			if err != nil {
-				exitWithError(fmt.Errorf("error validating targets: %v", err))
-			}
-			if valid {
-				glog.V(1).Info("== Running control plane checks ==")
-				runChecks(check.CONTROLPLANE, loadConfig(check.CONTROLPLANE, bv), detecetedKubeVersion)
-			}
-		} else {
-			glog.V(1).Info("== Skipping master checks ==")
-		}
-
-		// Etcd is only valid for CIS 1.5 and later,
-		// this a gatekeeper for previous versions.
-		valid, err := validTargets(bv, []string{string(check.ETCD)}, viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("error validating targets: %v", err))
-		}
-		if valid && isEtcd() {
-			glog.V(1).Info("== Running etcd checks ==")
-			runChecks(check.ETCD, loadConfig(check.ETCD, bv), detecetedKubeVersion)
-		} else {
-			glog.V(1).Info("== Skipping etcd checks ==")
-		}
-
-		glog.V(1).Info("== Running node checks ==")
-		runChecks(check.NODE, loadConfig(check.NODE, bv), detecetedKubeVersion)
-
-		// Policies is only valid for CIS 1.5 and later,
-		// this a gatekeeper for previous versions.
-		valid, err = validTargets(bv, []string{string(check.POLICIES)}, viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("error validating targets: %v", err))
-		}
-		if valid {
-			glog.V(1).Info("== Running policies checks ==")
-			runChecks(check.POLICIES, loadConfig(check.POLICIES, bv), detecetedKubeVersion)
-		} else {
-			glog.V(1).Info("== Skipping policies checks ==")
-		}
-
-		// Managedservices is only valid for GKE 1.0 and later,
-		// this a gatekeeper for previous versions.
-		valid, err = validTargets(bv, []string{string(check.MANAGEDSERVICES)}, viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("error validating targets: %v", err))
-		}
-		if valid {
-			glog.V(1).Info("== Running managed services checks ==")
-			runChecks(check.MANAGEDSERVICES, loadConfig(check.MANAGEDSERVICES, bv), detecetedKubeVersion)
-		} else {
-			glog.V(1).Info("== Skipping managed services checks ==")
+		if err := scanNode("", nil, true); err != nil {
+			exitOnError(err)
 		}
 
 		writeOutput(controlsCollection)
@@ -146,10 +106,7 @@ func Execute() {
 	goflag.CommandLine.Parse([]string{})
 
 	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		// flush before exit non-zero
-		glog.Flush()
-		os.Exit(-1)
+		exitWithError(err)
 	}
 	// flush before exit
 	glog.Flush()
@@ -159,34 +116,60 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Output control
-	RootCmd.PersistentFlags().IntVar(&exitCode, "exit-code", 0, "Specify the exit code for when checks fail")
+	RootCmd.PersistentFlags().Float64Var(&failUnder, "fail-under", 0, "Exit with the --exit-code (or 1) when the overall compliance score is below this percentage")
+	RootCmd.PersistentFlags().StringVar(&failOn, "fail-on", "", `Comma-delimited states (e.g. "FAIL,WARN") or severities (e.g. "high") of checks that make kube-bench exit with the --exit-code (or 1)`)
 	RootCmd.PersistentFlags().BoolVar(&noResults, "noresults", false, "Disable printing of results section")
 	RootCmd.PersistentFlags().BoolVar(&noSummary, "nosummary", false, "Disable printing of summary section")
 	RootCmd.PersistentFlags().BoolVar(&noRemediations, "noremediations", false, "Disable printing of remediations section")
 	RootCmd.PersistentFlags().BoolVar(&noTotals, "nototals", false, "Disable printing of totals for failed, passed, ... checks across all sections")
 	RootCmd.PersistentFlags().BoolVar(&jsonFmt, "json", false, "Prints the results as JSON")
 	RootCmd.PersistentFlags().BoolVar(&junitFmt, "junit", false, "Prints the results as JUnit")
+	RootCmd.PersistentFlags().BoolVar(&sarifFmt, "sarif", false, "Prints the results as SARIF 2.1.0")
+	RootCmd.PersistentFlags().BoolVar(&htmlFmt, "html", false, "Prints the results as a self-contained HTML report")
+	RootCmd.PersistentFlags().BoolVar(&markdownFmt, "markdown", false, "Prints the results as GitHub-flavored Markdown")
+	RootCmd.PersistentFlags().BoolVar(&markdownCondensed, "markdown-condensed", false, "Only show the totals and failed checks in the Markdown report")
+	RootCmd.PersistentFlags().BoolVar(&csvFmt, "csv", false, "Prints the results as CSV, one row per check")
+	RootCmd.PersistentFlags().BoolVar(&ocsfFmt, "ocsf", false, "Prints the results as OCSF Compliance Finding events, one JSON object per line")
+	RootCmd.PersistentFlags().BoolVar(&xccdfFmt, "xccdf", false, "Prints the results as an XCCDF 1.2 TestResult")
+	RootCmd.PersistentFlags().BoolVar(&arfFmt, "arf", false, "Prints the results as XCCDF 1.2 TestResults wrapped in an Asset Reporting Format collection")
+	RootCmd.PersistentFlags().BoolVar(&cklFmt, "ckl", false, "Prints the results of a STIG benchmark as a STIG Viewer .ckl checklist")
+	RootCmd.PersistentFlags().BoolVar(&cklbFmt, "cklb", false, "Prints the results of a STIG benchmark as a STIG Viewer .cklb checklist")
+	RootCmd.PersistentFlags().BoolVar(&oscalFmt, "oscal", false, "Prints the results as OSCAL Assessment Results")
+	RootCmd.PersistentFlags().StringVar(&outputTemplate, "output-template", "", "Render the results through this Go text/template file")
+	RootCmd.PersistentFlags().BoolVar(&streamFmt, "stream", false, "Print events as NDJSON to stdout while the checks run")
+	RootCmd.PersistentFlags().BoolVar(&prometheusFmt, "prometheus", false, "Prints the results as Prometheus metrics, written atomically to --outputfile for the node_exporter textfile collector")
 	RootCmd.PersistentFlags().BoolVar(&pgSQL, "pgsql", false, "Save the results to PostgreSQL")
 	RootCmd.PersistentFlags().BoolVar(&aSFF, "asff", false, "Send the results to AWS Security Hub")
 	RootCmd.PersistentFlags().BoolVar(&filterOpts.Scored, "scored", true, "Run the scored CIS checks")
 	RootCmd.PersistentFlags().BoolVar(&filterOpts.Unscored, "unscored", true, "Run the unscored CIS checks")
 	RootCmd.PersistentFlags().StringVar(&skipIds, "skip", "", "List of comma separated values of checks to be skipped")
+	RootCmd.PersistentFlags().StringVar(&waiversFile, "waivers", "", "YAML file of accepted-risk waivers that report matching failed checks as WAIVED")
+	RootCmd.PersistentFlags().StringVar(&attestationsFile, "attestations", "", "YAML file of attestations recorded with 'kube-bench attest' that set the result of manual checks")
+	RootCmd.PersistentFlags().StringVar(&baselineFile, "baseline", "", "JSON results of a previous run; checks that failed in it are reported as known and don't make kube-bench exit with --exit-code")
+	RootCmd.PersistentFlags().BoolVar(&updateBaseline, "update-baseline", false, "Write the results of this run to the --baseline file")
 	RootCmd.PersistentFlags().BoolVar(&includeTestOutput, "include-test-output", false, "Prints the actual result when test fails")
-	RootCmd.PersistentFlags().StringVar(&outputFile, "outputfile", "", "Writes the results to output file when run with --json or --junit")
+	RootCmd.PersistentFlags().StringVar(&outputFile, "outputfile", "", "Writes the results to output file when run with --json, --junit, --sarif, --html, --markdown, --csv, --ocsf, --xccdf, --arf, --ckl, --cklb, --oscal, --output-template or --prometheus")
+	RootCmd.PersistentFlags().StringArrayVar(&outputSpecs, "output", []string{}, `Write the results in a format to a destination, can be repeated. Format is format[=destination][,optional], e.g. --output text --output json=results.json`)
 
 	RootCmd.PersistentFlags().StringVarP(
 		&filterOpts.CheckList,
 		"check",
 		"c",
 		"",
-		`A comma-delimited list of checks to run as specified in CIS document. Example --check="1.1.1,1.1.2"`,
+		`A comma-delimited list of checks to run as specified in CIS document. Globs and ranges are supported. Example --check="1.1.1,1.2.*,4.2.1-4.2.6"`,
 	)
 	RootCmd.PersistentFlags().StringVarP(
 		&filterOpts.GroupList,
 		"group",
 		"g",
 		"",
-		`Run all the checks under this comma-delimited list of groups. Example --group="1.1"`,
+		`Run all the checks under this comma-delimited list of groups. Globs and ranges are supported. Example --group="1.1,4.*"`,
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&filterOpts.Expression,
+		"filter",
+		"",
+		`A comma-delimited filter expression of check IDs, "group:<id>" and "tag:<name>" terms, prefix a term with "!" to exclude it. Example --filter="1.2.*,!1.2.3,tag:kubelet"`,
 	)
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./cfg/config.yaml)")
 	RootCmd.PersistentFlags().StringVarP(&cfgDir, "config-dir", "D", cfgDir, "config directory")
@@ -230,8 +213,7 @@ func initConfig() {
 			configFileError = err
 		} else {
 			// Config file was found but another error was produced
-			colorPrint(check.FAIL, fmt.Sprintf("Failed to read config file: %v\n", err))
-			os.Exit(1)
+			exitWithOutcome(outcomeConfigNotFound, fmt.Errorf("Failed to read config file: %v", err))
 		}
 	}
 }
@@ -6,10 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/golang/glog"
-	"github.com/khulnasoft-lab/kube-bench/check"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 func init() {
@@ -32,58 +29,15 @@ var runCmd = &cobra.Command{
 			exitWithError(fmt.Errorf("unable to get `targets` from command line :%v", err))
 		}
 
-		bv, err := getBenchmarkVersion(kubeVersion, benchmarkVersion, getPlatformInfo(), viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("unable to get benchmark version. error: %v", err))
-		}
-
-		glog.V(2).Infof("Checking targets %v for %v", targets, bv)
-		benchmarkVersionToTargetsMap, err := loadTargetMapping(viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("error loading targets: %v", err))
-		}
-		valid, err := validTargets(bv, targets, viper.GetViper())
-		if err != nil {
-			exitWithError(fmt.Errorf("error validating targets: %v", err))
-		}
-		if len(targets) > 0 && !valid {
-			exitWithError(fmt.Errorf(`The specified --targets "%s" are not configured for the CIS Benchmark %s\n Valid targets %v`, strings.Join(targets, ","), bv, benchmarkVersionToTargetsMap[bv]))
-		}
-
-		// Merge version-specific config if any.
-		path := filepath.Join(cfgDir, bv)
-		err = mergeConfig(path)
-		if err != nil {
-			exitWithError(fmt.Errorf("Error in mergeConfig: %v\n", err))
-		}
-
-		err = run(targets, bv)
-		if err != nil {
-			exitWithError(fmt.Errorf("Error in run: %v\n", err))
+		if err := scanNode("", targets, false); err != nil {
+			exitOnError(err)
 		}
 
+		writeOutput(controlsCollection)
 		os.Exit(exitCodeSelection(controlsCollection))
 	},
 }
 
-func run(targets []string, benchmarkVersion string) (err error) {
-	yamlFiles, err := getTestYamlFiles(targets, benchmarkVersion)
-	if err != nil {
-		return err
-	}
-
-	glog.V(3).Infof("Running tests from files %v\n", yamlFiles)
-
-	for _, yamlFile := range yamlFiles {
-		_, name := filepath.Split(yamlFile)
-		testType := check.NodeType(strings.Split(name, ".")[0])
-		runChecks(testType, yamlFile, detecetedKubeVersion)
-	}
-
-	writeOutput(controlsCollection)
-	return nil
-}
-
 func getTestYamlFiles(targets []string, benchmarkVersion string) (yamlFiles []string, err error) {
 	// Check that the specified targets have corresponding YAML files in the config directory
 	configFileDirectory := filepath.Join(cfgDir, benchmarkVersion)
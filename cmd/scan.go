@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+)
+
+// targetControlsFiles are the controls files the targets of the last scan
+// were loaded from.
+var targetControlsFiles = make(map[check.NodeType]string)
+
+// scanNode runs the benchmark on this node and leaves the results in
+// controlsCollection. With detect, the targets are those whose components run
+// on the node; otherwise they are the given targets, or all the targets of the
+// benchmark. Errors are returned rather than exiting, so that long-running
+// commands can scan again.
+func scanNode(scanID string, targets []string, detect bool) error {
+	controlsCollection = nil
+	targetControlsFiles = make(map[check.NodeType]string)
+	if updateBaseline && baselineFile == "" {
+		return fmt.Errorf("--update-baseline requires --baseline")
+	}
+
+	platform := getPlatformInfo()
+	bv, err := getBenchmarkVersion(kubeVersion, benchmarkVersion, platform, viper.GetViper())
+	if err != nil {
+		return withOutcome(outcomeBenchmarkNotDetected, fmt.Errorf("unable to determine benchmark version: %v", err))
+	}
+	glog.V(1).Infof("Running checks for benchmark %v", bv)
+
+	if !detect {
+		if err := prepareTargets(targets, bv); err != nil {
+			return err
+		}
+	}
+
+	startScanMetadata(scanID, bv, platform)
+	emitScanStarted(bv)
+
+	if detect {
+		err = runDetectedTargets(bv)
+	} else {
+		err = runTargets(targets, bv)
+	}
+	if err != nil {
+		return err
+	}
+
+	return checkFilterMatched(filterOpts, controlsCollection)
+}
+
+// prepareTargets checks that the targets are configured for the benchmark and
+// merges the version-specific config.
+func prepareTargets(targets []string, bv string) error {
+	glog.V(2).Infof("Checking targets %v for %v", targets, bv)
+	benchmarkVersionToTargetsMap, err := loadTargetMapping(viper.GetViper())
+	if err != nil {
+		return withOutcome(outcomeConfigNotFound, fmt.Errorf("error loading targets: %v", err))
+	}
+	valid, err := validTargets(bv, targets, viper.GetViper())
+	if err != nil {
+		return withOutcome(outcomeConfigNotFound, fmt.Errorf("error validating targets: %v", err))
+	}
+	if len(targets) > 0 && !valid {
+		return fmt.Errorf(`The specified --targets "%s" are not configured for the CIS Benchmark %s\n Valid targets %v`, strings.Join(targets, ","), bv, benchmarkVersionToTargetsMap[bv])
+	}
+
+	// Merge version-specific config if any.
+	path := filepath.Join(cfgDir, bv)
+	if err := mergeConfig(path); err != nil {
+		return withOutcome(outcomeConfigNotFound, fmt.Errorf("Error in mergeConfig: %v\n", err))
+	}
+	return nil
+}
+
+// runTargets runs the checks of the given targets, or of all the targets of the benchmark.
+func runTargets(targets []string, benchmarkVersion string) error {
+	yamlFiles, err := getTestYamlFiles(targets, benchmarkVersion)
+	if err != nil {
+		return err
+	}
+
+	glog.V(3).Infof("Running tests from files %v\n", yamlFiles)
+
+	for _, yamlFile := range yamlFiles {
+		_, name := filepath.Split(yamlFile)
+		testType := check.NodeType(strings.Split(name, ".")[0])
+		if err := runChecks(testType, yamlFile, detecetedKubeVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runDetectedTargets runs the checks of the targets whose components run on this node.
+func runDetectedTargets(bv string) error {
+	runTarget := func(nodetype check.NodeType) error {
+		file, err := loadConfig(nodetype, bv)
+		if err != nil {
+			return err
+		}
+		return runChecks(nodetype, file, detecetedKubeVersion)
+	}
+
+	if isMaster() {
+		glog.V(1).Info("== Running master checks ==")
+		if err := runTarget(check.MASTER); err != nil {
+			return err
+		}
+
+		// Control Plane is only valid for CIS 1.5 and later,
+		// this a gatekeeper for previous versions
+		valid, err := validTargets(bv, []string{string(check.CONTROLPLANE)}, viper.GetViper())
+		if err != nil {
+			return withOutcome(outcomeConfigNotFound, fmt.Errorf("error validating targets: %v", err))
+		}
+		if valid {
+			glog.V(1).Info("== Running control plane checks ==")
+			if err := runTarget(check.CONTROLPLANE); err != nil {
+				return err
+			}
+		}
+	} else {
+		glog.V(1).Info("== Skipping master checks ==")
+	}
+
+	// Etcd is only valid for CIS 1.5 and later,
+	// this a gatekeeper for previous versions.
+	valid, err := validTargets(bv, []string{string(check.ETCD)}, viper.GetViper())
+	if err != nil {
+		return withOutcome(outcomeConfigNotFound, fmt.Errorf("error validating targets: %v", err))
+	}
+	if valid && isEtcd() {
+		glog.V(1).Info("== Running etcd checks ==")
+		if err := runTarget(check.ETCD); err != nil {
+			return err
+		}
+	} else {
+		glog.V(1).Info("== Skipping etcd checks ==")
+	}
+
+	glog.V(1).Info("== Running node checks ==")
+	if err := runTarget(check.NODE); err != nil {
+		return err
+	}
+
+	// Policies is only valid for CIS 1.5 and later,
+	// this a gatekeeper for previous versions.
+	valid, err = validTargets(bv, []string{string(check.POLICIES)}, viper.GetViper())
+	if err != nil {
+		return withOutcome(outcomeConfigNotFound, fmt.Errorf("error validating targets: %v", err))
+	}
+	if valid {
+		glog.V(1).Info("== Running policies checks ==")
+		if err := runTarget(check.POLICIES); err != nil {
+			return err
+		}
+	} else {
+		glog.V(1).Info("== Skipping policies checks ==")
+	}
+
+	// Managedservices is only valid for GKE 1.0 and later,
+	// this a gatekeeper for previous versions.
+	valid, err = validTargets(bv, []string{string(check.MANAGEDSERVICES)}, viper.GetViper())
+	if err != nil {
+		return withOutcome(outcomeConfigNotFound, fmt.Errorf("error validating targets: %v", err))
+	}
+	if valid {
+		glog.V(1).Info("== Running managed services checks ==")
+		if err := runTarget(check.MANAGEDSERVICES); err != nil {
+			return err
+		}
+	} else {
+		glog.V(1).Info("== Skipping managed services checks ==")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Shows the JSON Schema of the JSON results.",
+	Long: `Shows the JSON Schema of the JSON results of this version of kube-bench.
+The results have a schema_version field with the version of the schema they follow.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(string(check.ResultSchema))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(schemaCmd)
+}
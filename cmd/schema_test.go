@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/khulnasoft-lab/kube-bench/internal/schematest"
+)
+
+// TestResultSchemaCompatibility checks that the results kube-bench writes, and
+// the results of earlier versions kept in testdata, match the result schema.
+func TestResultSchemaCompatibility(t *testing.T) {
+	for _, f := range []string{"result.json", "result_no_totals.json", "controlsCollection.json", "passedControlsCollection.json"} {
+		t.Run(f, func(t *testing.T) {
+			in, err := os.ReadFile(filepath.Join("testdata", f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := schematest.Validate(check.ResultSchema, in); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	controls, err := parseControlsJsonFile("./testdata/controlsCollection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		noTotals = false
+		scanMetadata = nil
+	}()
+	scanMetadata = &check.Metadata{ScanID: "id-1", Node: "node-1", Benchmark: "cis-1.5", StartTime: time.Now()}
+	finishScanMetadata()
+
+	for _, nt := range []bool{false, true} {
+		noTotals = nt
+		dest := filepath.Join(t.TempDir(), "result.json")
+		if err := writeJSONOutput(controls, dest); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.ReadFile(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := schematest.Validate(check.ResultSchema, out); err != nil {
+			t.Errorf("nototals=%t: %v", nt, err)
+		}
+	}
+}
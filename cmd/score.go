@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+)
+
+// severityOrder ranks the well-known severities, so that --fail-on=high also
+// matches critical failures.
+var severityOrder = map[string]int{
+	"info":     0,
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// scoreWeights returns the weights of check severities from the score_weights config section.
+func scoreWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	for k := range viper.GetStringMap("score_weights") {
+		weights[strings.ToLower(k)] = viper.GetFloat64("score_weights." + k)
+	}
+	return weights
+}
+
+// complianceGatesFailed reports whether the --fail-under or --fail-on gates are not met.
+func complianceGatesFailed(controlsCollection []*check.Controls) bool {
+	if failUnder > 0 {
+		score := check.ComputeScore(controlsCollection, scoreWeights())
+		if score.Overall < failUnder {
+			glog.V(1).Infof("Compliance score %.2f%% is below %.2f%%", score.Overall, failUnder)
+			return true
+		}
+	}
+	return failOn != "" && matchesFailOn(controlsCollection, failOn)
+}
+
+// matchesFailOn reports whether any check matches the --fail-on list of states and severities.
+// A state matches checks in that state; a severity matches failed checks of that severity or higher.
+// Failed checks known from the baseline don't match.
+func matchesFailOn(controlsCollection []*check.Controls, failOn string) bool {
+	states := make(map[check.State]bool)
+	var severities []string
+	for _, v := range strings.Split(failOn, ",") {
+		v = strings.TrimSpace(v)
+		switch s := check.State(strings.ToUpper(v)); s {
+		case "":
+		case check.PASS, check.FAIL, check.WARN, check.INFO, check.WAIVED:
+			states[s] = true
+		default:
+			severities = append(severities, strings.ToLower(v))
+		}
+	}
+
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				if c.Known {
+					continue
+				}
+				if states[c.State] {
+					return true
+				}
+				if c.State == check.FAIL && severityAtLeast(c.Severity, severities) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func severityAtLeast(severity string, thresholds []string) bool {
+	severity = strings.ToLower(severity)
+	for _, t := range thresholds {
+		if severity == t {
+			return true
+		}
+		rank, known := severityOrder[severity]
+		threshold, thresholdKnown := severityOrder[t]
+		if known && thresholdKnown && rank >= threshold {
+			return true
+		}
+	}
+	return false
+}
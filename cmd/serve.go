@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Statuses of the scans run by the server.
+const (
+	scanRunning  = "running"
+	scanFinished = "finished"
+	scanFailed   = "failed"
+)
+
+var (
+	serveAddr     string
+	serveInterval time.Duration
+	serveHistory  int
+)
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringSliceP("targets", "s", []string{},
+		`Specify targets of the benchmark to run, as for the run command.
+	If no targets are specified, run the targets whose components run on the node.`)
+	serveCmd.Flags().StringVar(&serveAddr, "listen", ":8080", "Address to serve the HTTP API on")
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", 0, "Run a scan at this interval, e.g. 1h; without it, scans only run at startup and on demand")
+	serveCmd.Flags().IntVar(&serveHistory, "history", 10, "Number of recent scans to keep")
+}
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run scans on a schedule or on demand and serve the results over HTTP",
+	Long: `Keeps kube-bench running, scanning the node at startup, every --interval and on
+POST /scans. The results of recent scans are served as JSON, and the latest as
+Prometheus metrics on /metrics.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		targets, err := cmd.Flags().GetStringSlice("targets")
+		if err != nil {
+			exitWithError(fmt.Errorf("unable to get `targets` from command line :%v", err))
+		}
+		if serveHistory < 1 {
+			exitWithError(fmt.Errorf("--history must be at least 1"))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		s := newScanServer(nodeScanner(targets), serveHistory)
+		if err := s.serve(ctx, serveAddr, serveInterval); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// scanRecord is a scan run by the server.
+type scanRecord struct {
+	ID        string                 `json:"id"`
+	Status    string                 `json:"status"`
+	StartTime time.Time              `json:"start_time"`
+	EndTime   *time.Time             `json:"end_time,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Totals    *check.Summary         `json:"totals,omitempty"`
+	Score     *float64               `json:"score,omitempty"`
+	Result    *check.OverallControls `json:"result,omitempty"`
+}
+
+// summary returns the record without its results, for listing scans.
+func (r scanRecord) summary() scanRecord {
+	if r.Result != nil {
+		r.Totals = &r.Result.Totals
+		if r.Result.Score != nil {
+			r.Score = &r.Result.Score.Overall
+		}
+	}
+	r.Result = nil
+	return r
+}
+
+// scanner runs a scan with the given ID and returns its results.
+type scanner func(id string) (*check.OverallControls, error)
+
+// nodeScanner scans this node as the run command does, and writes the
+// results to the outputs given with --output or in the config file, if any.
+func nodeScanner(targets []string) scanner {
+	return func(id string) (*check.OverallControls, error) {
+		if err := scanNode(id, targets, len(targets) == 0); err != nil {
+			return nil, err
+		}
+		finishScanMetadata()
+		sortControls(controlsCollection)
+		emitScanFinished(controlsCollection)
+		writeConfiguredOutputs(controlsCollection)
+		return newOverallControls(controlsCollection), nil
+	}
+}
+
+// writeConfiguredOutputs writes the results to the outputs given with --output
+// or in the config file. Long-running commands have no default output, and
+// failing outputs are logged rather than stopping them.
+func writeConfiguredOutputs(controlsCollection []*check.Controls) {
+	if len(outputSpecs) == 0 && !viper.IsSet("outputs") {
+		return
+	}
+	sinks, err := getOutputSinks()
+	if err == nil {
+		err = writeOutputs(controlsCollection, sinks)
+	}
+	if err != nil {
+		glog.Warningf("failed to write outputs: %v", err)
+	}
+}
+
+// scanServer runs scans one at a time and keeps the most recent ones.
+type scanServer struct {
+	scan    scanner
+	history int
+
+	mu      sync.Mutex
+	running bool
+	// records are the recent scans, oldest first.
+	records []*scanRecord
+	counts  map[string]int
+}
+
+func newScanServer(scan scanner, history int) *scanServer {
+	return &scanServer{scan: scan, history: history, counts: make(map[string]int)}
+}
+
+// start starts a scan in the background, unless one is already running.
+func (s *scanServer) start() (scanRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return scanRecord{}, false
+	}
+
+	rec := &scanRecord{ID: uuid.NewString(), Status: scanRunning, StartTime: time.Now().UTC()}
+	s.running = true
+	s.records = append(s.records, rec)
+	if len(s.records) > s.history {
+		s.records = s.records[len(s.records)-s.history:]
+	}
+
+	go s.run(rec)
+	return *rec, true
+}
+
+func (s *scanServer) run(rec *scanRecord) {
+	result, err := safeScan(s.scan, rec.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	end := time.Now().UTC()
+	rec.EndTime = &end
+	if err != nil {
+		rec.Status = scanFailed
+		rec.Error = err.Error()
+		emitEvent(streamEvent{Event: eventScanFailed, Error: err.Error()})
+		glog.Warningf("scan %s failed: %v", rec.ID, err)
+	} else {
+		rec.Status = scanFinished
+		rec.Result = result
+	}
+	s.counts[rec.Status]++
+	s.running = false
+}
+
+// safeScan runs a scan, turning a panic into an error so that a broken check
+// does not stop a long-running command.
+func safeScan(scan scanner, id string) (result *check.OverallControls, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scan panicked: %v", r)
+		}
+	}()
+	return scan(id)
+}
+
+// find returns the scan with the given ID.
+func (s *scanServer) find(id string) (scanRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rec := range s.records {
+		if rec.ID == id {
+			return *rec, true
+		}
+	}
+	return scanRecord{}, false
+}
+
+// latest returns the most recent finished scan.
+func (s *scanServer) latest() (scanRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if s.records[i].Status == scanFinished {
+			return *s.records[i], true
+		}
+	}
+	return scanRecord{}, false
+}
+
+// list returns the summaries of the recent scans, newest first.
+func (s *scanServer) list() []scanRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]scanRecord, 0, len(s.records))
+	for i := len(s.records) - 1; i >= 0; i-- {
+		list = append(list, s.records[i].summary())
+	}
+	return list
+}
+
+func (s *scanServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.HandleFunc("POST /scans", func(w http.ResponseWriter, r *http.Request) {
+		rec, ok := s.start()
+		if !ok {
+			writeJSONError(w, http.StatusConflict, "a scan is already running")
+			return
+		}
+		w.Header().Set("Location", "/scans/"+rec.ID)
+		writeJSON(w, http.StatusAccepted, rec)
+	})
+	mux.HandleFunc("GET /scans", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.list())
+	})
+	mux.HandleFunc("GET /scans/latest", func(w http.ResponseWriter, r *http.Request) {
+		rec, ok := s.latest()
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, "no scan has finished yet")
+			return
+		}
+		writeJSON(w, http.StatusOK, rec)
+	})
+	mux.HandleFunc("GET /scans/{id}", func(w http.ResponseWriter, r *http.Request) {
+		rec, ok := s.find(r.PathValue("id"))
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, rec)
+	})
+	return mux
+}
+
+// handleMetrics serves the results of the latest finished scan, and counts of
+// the scans run, as Prometheus metrics.
+func (s *scanServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if rec, ok := s.latest(); ok {
+		end := *rec.EndTime
+		if md := rec.Result.Metadata; md != nil && !md.EndTime.IsZero() {
+			end = md.EndTime
+		}
+		w.Write(renderPrometheus(rec.Result.Controls, rec.Result.Metadata, end))
+	}
+
+	s.mu.Lock()
+	var m prometheusMetrics
+	m.family("kube_bench_scans_total", "counter", "Number of scans run by the server, by status.")
+	for _, status := range []string{scanFinished, scanFailed} {
+		m.sample("kube_bench_scans_total", float64(s.counts[status]), "status", status)
+	}
+	running := 0.0
+	if s.running {
+		running = 1
+	}
+	m.family("kube_bench_scan_running", "gauge", "Whether a scan is running.")
+	m.sample("kube_bench_scan_running", running)
+	s.mu.Unlock()
+
+	w.Write(m.b.Bytes())
+}
+
+// serve serves the API until the context is done. The first scan runs at
+// startup, and then every interval if it is set.
+func (s *scanServer) serve(ctx context.Context, addr string, interval time.Duration) error {
+	srv := &http.Server{Addr: addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+	glog.Infof("Serving the kube-bench API on %s", addr)
+
+	go s.schedule(ctx, interval)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func (s *scanServer) schedule(ctx context.Context, interval time.Duration) {
+	s.start()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, ok := s.start(); !ok {
+				glog.V(1).Info("Skipping the scheduled scan, a scan is already running")
+			}
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		glog.Warningf("failed to write response: %v", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner returns results, or an error if fail is set, once release is closed.
+type fakeScanner struct {
+	release chan struct{}
+	fail    bool
+}
+
+func (f *fakeScanner) scan(id string) (*check.OverallControls, error) {
+	<-f.release
+	if f.fail {
+		return nil, errors.New("no benchmark")
+	}
+	score := check.Score{Overall: 50}
+	return &check.OverallControls{
+		Metadata: &check.Metadata{ScanID: id, Benchmark: "cis-1.9", EndTime: time.Now()},
+		Controls: prometheusTestControls(),
+		Totals:   check.Summary{Pass: 1, Fail: 1},
+		Score:    &score,
+	}, nil
+}
+
+func waitForScan(t *testing.T, s *scanServer, id string) scanRecord {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		if rec, ok := s.find(id); ok && rec.Status != scanRunning {
+			return rec
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("scan %s did not finish", id)
+	return scanRecord{}
+}
+
+func request(t *testing.T, h http.Handler, method, path string, v interface{}) *http.Response {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	resp := w.Result()
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp
+}
+
+func TestScanServer(t *testing.T) {
+	f := &fakeScanner{release: make(chan struct{})}
+	s := newScanServer(f.scan, 10)
+	h := s.handler()
+
+	resp := request(t, h, http.MethodGet, "/scans/latest", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var started scanRecord
+	resp = request(t, h, http.MethodPost, "/scans", &started)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, scanRunning, started.Status)
+	assert.Equal(t, "/scans/"+started.ID, resp.Header.Get("Location"))
+
+	resp = request(t, h, http.MethodPost, "/scans", nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "only one scan runs at a time")
+
+	close(f.release)
+	waitForScan(t, s, started.ID)
+
+	var latest scanRecord
+	resp = request(t, h, http.MethodGet, "/scans/latest", &latest)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, started.ID, latest.ID)
+	assert.Equal(t, scanFinished, latest.Status)
+	require.NotNil(t, latest.Result)
+	assert.Equal(t, started.ID, latest.Result.Metadata.ScanID)
+
+	var byID scanRecord
+	resp = request(t, h, http.MethodGet, "/scans/"+started.ID, &byID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, latest.ID, byID.ID)
+
+	resp = request(t, h, http.MethodGet, "/scans/unknown", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var list []scanRecord
+	request(t, h, http.MethodGet, "/scans", &list)
+	require.Len(t, list, 1)
+	assert.Nil(t, list[0].Result, "the list only has summaries")
+	assert.Equal(t, &check.Summary{Pass: 1, Fail: 1}, list[0].Totals)
+	assert.Equal(t, 50.0, *list[0].Score)
+
+	resp = request(t, h, http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = request(t, h, http.MethodGet, "/metrics", nil)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `kube_bench_check_status{benchmark="cis-1.9",target="node",check="4.1.2",scored="false"} 1`)
+	assert.Contains(t, string(body), `kube_bench_scans_total{status="finished"} 1`)
+	assert.Contains(t, string(body), "kube_bench_scan_running 0")
+}
+
+func TestScanServerFailedScan(t *testing.T) {
+	f := &fakeScanner{release: make(chan struct{}), fail: true}
+	close(f.release)
+	s := newScanServer(f.scan, 10)
+
+	rec, ok := s.start()
+	require.True(t, ok)
+	rec = waitForScan(t, s, rec.ID)
+	assert.Equal(t, scanFailed, rec.Status)
+	assert.Equal(t, "no benchmark", rec.Error)
+
+	_, ok = s.latest()
+	assert.False(t, ok, "failed scans have no results")
+
+	resp := request(t, s.handler(), http.MethodGet, "/metrics", nil)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `kube_bench_scans_total{status="failed"} 1`)
+	assert.NotContains(t, string(body), "kube_bench_check_status")
+}
+
+func TestScanServerPanic(t *testing.T) {
+	s := newScanServer(func(id string) (*check.OverallControls, error) { panic("boom") }, 10)
+	rec, ok := s.start()
+	require.True(t, ok)
+	rec = waitForScan(t, s, rec.ID)
+	assert.Equal(t, scanFailed, rec.Status)
+	assert.True(t, strings.Contains(rec.Error, "boom"))
+}
+
+func TestScanServerHistory(t *testing.T) {
+	f := &fakeScanner{release: make(chan struct{})}
+	close(f.release)
+	s := newScanServer(f.scan, 2)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		rec, ok := s.start()
+		require.True(t, ok)
+		waitForScan(t, s, rec.ID)
+		ids = append(ids, rec.ID)
+	}
+
+	list := s.list()
+	require.Len(t, list, 2)
+	assert.Equal(t, ids[2], list[0].ID, "newest first")
+	assert.Equal(t, ids[1], list[1].ID)
+	_, ok := s.find(ids[0])
+	assert.False(t, ok)
+}
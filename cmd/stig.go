@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+func writeCKLOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := check.STIGChecklist(controlsCollection, resultNode())
+	if err != nil {
+		return fmt.Errorf("failed to output STIG checklist: %v", err)
+	}
+	return printOutput(string(out), destination)
+}
+
+func writeCKLBOutput(controlsCollection []*check.Controls, destination string) error {
+	out, err := check.STIGChecklistJSON(controlsCollection, resultNode())
+	if err != nil {
+		return fmt.Errorf("failed to output STIG checklist: %v", err)
+	}
+	return printOutput(string(out), destination)
+}
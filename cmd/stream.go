@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// Stream event types, in the order they are emitted.
+const (
+	eventScanStarted    = "scan_started"
+	eventTargetStarted  = "target_started"
+	eventCheck          = "check"
+	eventTargetFinished = "target_finished"
+	eventScanFinished   = "scan_finished"
+	eventScanFailed     = "scan_failed"
+	// eventCheckDrifted is written by the daemon command when a check differs
+	// from the previous scan.
+	eventCheckDrifted = "check_drifted"
+)
+
+// streamEvent is a line of the --stream output.
+type streamEvent struct {
+	Event            string             `json:"event"`
+	Time             string             `json:"time"`
+	ScanID           string             `json:"scan_id,omitempty"`
+	KubeBenchVersion string             `json:"kube_bench_version,omitempty"`
+	Benchmark        string             `json:"benchmark,omitempty"`
+	DetectedVersion  string             `json:"detected_version,omitempty"`
+	Node             string             `json:"node,omitempty"`
+	Target           check.NodeType     `json:"target,omitempty"`
+	Group            string             `json:"group,omitempty"`
+	Check            *check.Check       `json:"check,omitempty"`
+	Summary          *check.Summary     `json:"summary,omitempty"`
+	Score            *check.Score       `json:"score,omitempty"`
+	Error            string             `json:"error,omitempty"`
+	PreviousScanID   string             `json:"previous_scan_id,omitempty"`
+	Change           *check.CheckChange `json:"change,omitempty"`
+}
+
+var (
+	streamMutex sync.Mutex
+	// streamWriter is where events are written, one JSON object per line.
+	streamWriter io.Writer = os.Stdout
+)
+
+// emitEvent writes an event when --stream is set. Each event is written as
+// soon as it happens, so that a scan that fails part way still has its results.
+func emitEvent(e streamEvent) {
+	if !streamFmt {
+		return
+	}
+	writeEvent(streamWriter, e)
+}
+
+// writeEvent writes an event to w as a line of JSON.
+func writeEvent(w io.Writer, e streamEvent) {
+	e.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	if e.ScanID == "" && scanMetadata != nil {
+		e.ScanID = scanMetadata.ScanID
+	}
+
+	out, err := json.Marshal(e)
+	if err != nil {
+		glog.Warningf("failed to encode %s event: %v", e.Event, err)
+		return
+	}
+
+	streamMutex.Lock()
+	defer streamMutex.Unlock()
+	fmt.Fprintln(w, string(out))
+}
+
+func emitScanStarted(benchmark string) {
+	emitEvent(streamEvent{
+		Event:            eventScanStarted,
+		KubeBenchVersion: KubeBenchVersion,
+		Benchmark:        benchmark,
+		DetectedVersion:  detecetedKubeVersion,
+		Node:             getNodeName(),
+	})
+}
+
+func emitScanFinished(controlsCollection []*check.Controls) {
+	if !streamFmt {
+		return
+	}
+	totals := getSummaryTotals(controlsCollection)
+	score := check.ComputeScore(controlsCollection, scoreWeights())
+	emitEvent(streamEvent{Event: eventScanFinished, Summary: &totals, Score: &score})
+}
+
+// streamRunner wraps a check.Runner and emits each check result as soon as it is known.
+type streamRunner struct {
+	runner    check.Runner
+	benchmark string
+	target    check.NodeType
+	groups    map[*check.Check]string
+}
+
+func newStreamRunner(runner check.Runner, controls *check.Controls) check.Runner {
+	groups := make(map[*check.Check]string)
+	for _, g := range controls.Groups {
+		for _, c := range g.Checks {
+			groups[c] = g.ID
+		}
+	}
+	return &streamRunner{runner: runner, benchmark: controls.Version, target: controls.Type, groups: groups}
+}
+
+// Run runs the check and emits its result.
+func (r *streamRunner) Run(c *check.Check) check.State {
+	state := r.runner.Run(c)
+	emitEvent(streamEvent{Event: eventCheck, Benchmark: r.benchmark, Target: r.target, Group: r.groups[c], Check: c})
+	return state
+}
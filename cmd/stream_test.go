@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamEvents(t *testing.T) {
+	var b bytes.Buffer
+	streamFmt = true
+	streamWriter = &b
+	defer func() {
+		streamFmt = false
+		streamWriter = os.Stdout
+	}()
+
+	controls := &check.Controls{
+		Version: "cis-1.9",
+		Type:    check.NODE,
+		Groups: []*check.Group{
+			{ID: "4.1", Checks: []*check.Check{{ID: "4.1.1"}, {ID: "4.1.2"}}},
+			{ID: "4.2", Checks: []*check.Check{{ID: "4.2.1"}}},
+		},
+	}
+
+	emitScanStarted("cis-1.9")
+	runner := newStreamRunner(stateRunner{state: check.FAIL}, controls)
+	controls.RunChecks(runner, func(*check.Group, *check.Check) bool { return true }, nil)
+	emitScanFinished([]*check.Controls{controls})
+
+	var events []streamEvent
+	scanner := bufio.NewScanner(&b)
+	for scanner.Scan() {
+		var e streamEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		assert.NotEmpty(t, e.Time)
+		events = append(events, e)
+	}
+
+	assert.Len(t, events, 5)
+	assert.Equal(t, eventScanStarted, events[0].Event)
+	assert.Equal(t, "cis-1.9", events[0].Benchmark)
+	for i, id := range []string{"4.1.1", "4.1.2", "4.2.1"} {
+		e := events[i+1]
+		assert.Equal(t, eventCheck, e.Event)
+		assert.Equal(t, check.NODE, e.Target)
+		assert.Equal(t, id, e.Check.ID)
+		assert.Equal(t, check.FAIL, e.Check.State)
+	}
+	assert.Equal(t, "4.2", events[3].Group)
+	assert.Equal(t, eventScanFinished, events[4].Event)
+	assert.Equal(t, 3, events[4].Summary.Fail)
+	assert.Equal(t, 0.0, events[4].Score.Overall)
+}
+
+func TestStreamReplacesDefaultTextOutput(t *testing.T) {
+	streamFmt = true
+	defer func() { streamFmt = false }()
+
+	sinks, err := getOutputSinks()
+	assert.NoError(t, err)
+	assert.Empty(t, sinks)
+}
+
+func TestEmitEventDisabled(t *testing.T) {
+	var b bytes.Buffer
+	streamWriter = &b
+	defer func() { streamWriter = os.Stdout }()
+
+	emitScanStarted("cis-1.9")
+	assert.Empty(t, b.String())
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// templateCheck is a check together with where it comes from, as listed by
+// the checks template function.
+type templateCheck struct {
+	*check.Check
+	Benchmark string
+	Target    check.NodeType
+	Group     *check.Group
+}
+
+// templateData is what output templates are given: the results as in the JSON
+// output, with the node, time, version and benchmarks of the HTML report.
+type templateData struct {
+	*check.OverallControls
+	Node       string
+	Generated  string
+	Version    string
+	Benchmarks []string
+}
+
+func newTemplateData(controlsCollection []*check.Controls) templateData {
+	report := newReportData(controlsCollection)
+	return templateData{
+		OverallControls: newOverallControls(controlsCollection),
+		Node:            report.Node,
+		Generated:       report.Generated,
+		Version:         report.Version,
+		Benchmarks:      report.Benchmarks,
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"checks":    templateChecks,
+	"withState": templateWithState,
+	"join":      strings.Join,
+	"truncate":  templateTruncate,
+	"json":      templateJSON,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+}
+
+// templateChecks lists the checks of all the controls.
+func templateChecks(controlsCollection []*check.Controls) []templateCheck {
+	var checks []templateCheck
+	for _, controls := range controlsCollection {
+		for _, g := range controls.Groups {
+			for _, c := range g.Checks {
+				checks = append(checks, templateCheck{Check: c, Benchmark: controls.Version, Target: controls.Type, Group: g})
+			}
+		}
+	}
+	return checks
+}
+
+// templateWithState keeps the checks in one of the comma-delimited states.
+func templateWithState(states string, checks []templateCheck) []templateCheck {
+	wanted := make(map[check.State]bool)
+	for _, s := range strings.Split(states, ",") {
+		wanted[check.State(strings.ToUpper(strings.TrimSpace(s)))] = true
+	}
+
+	var filtered []templateCheck
+	for _, c := range checks {
+		if wanted[c.State] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// templateTruncate shortens s to at most n runes, ending with "..." when cut.
+func templateTruncate(n int, s string) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
+
+func templateJSON(v interface{}) (string, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// renderTemplate renders the results through a text/template file.
+func renderTemplate(path string, data templateData) ([]byte, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening output template %s: %v", path, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(in))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output template %s: %v", path, err)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render output template %s: %v", path, err)
+	}
+	return b.Bytes(), nil
+}
+
+func writeTemplateOutput(controlsCollection []*check.Controls, destination string) error {
+	if outputTemplate == "" {
+		return fmt.Errorf("the template output needs a template file given with --output-template")
+	}
+	out, err := renderTemplate(outputTemplate, newTemplateData(controlsCollection))
+	if err != nil {
+		return err
+	}
+	return printOutput(strings.TrimSuffix(string(out), "\n"), destination)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	defer func() { scanMetadata = nil }()
+	scanMetadata = &check.Metadata{ScanID: "scan-1", Node: "master-1"}
+
+	controls := []*check.Controls{{
+		Version: "cis-1.9",
+		Type:    check.MASTER,
+		Groups: []*check.Group{{
+			ID: "1.1",
+			Checks: []*check.Check{
+				{ID: "1.1.1", Text: "Ensure that the API server pod specification file permissions are set", State: check.FAIL, ActualValue: `mode "777"`},
+				{ID: "1.1.2", Text: "Ensure ownership", State: check.PASS},
+				{ID: "1.1.3", Text: "Manual", State: check.WARN},
+			},
+		}},
+		Summary: check.Summary{Pass: 1, Fail: 1, Warn: 1},
+	}}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tickets.tmpl")
+	tmpl := `{{join .Benchmarks ","}} fail={{.Totals.Fail}} score={{.Score.Overall}} schema={{.SchemaVersion}} scan={{.Metadata.ScanID}} node={{.Node}}
+{{range checks .Controls | withState "fail,warn"}}{{.Target}}/{{.Group.ID}} {{.ID}} {{truncate 20 .Text}} {{json .ActualValue}}
+{{end}}`
+	if err := os.WriteFile(path, []byte(tmpl), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := renderTemplate(path, newTemplateData(controls))
+	assert.NoError(t, err)
+	assert.Equal(t, `cis-1.9 fail=1 score=50 schema=`+check.SchemaVersion+` scan=scan-1 node=master-1
+master/1.1 1.1.1 Ensure that the A... "mode \"777\""
+master/1.1 1.1.3 Manual ""
+`, string(out))
+
+	if err := os.WriteFile(path, []byte("{{.Missing"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = renderTemplate(path, newTemplateData(controls))
+	assert.Error(t, err)
+
+	_, err = renderTemplate(filepath.Join(dir, "missing.tmpl"), newTemplateData(controls))
+	assert.Error(t, err)
+}
+
+func TestTemplateTruncate(t *testing.T) {
+	assert.Equal(t, "short", templateTruncate(10, "short"))
+	assert.Equal(t, "abcdefg...", templateTruncate(10, "abcdefghijklmnop"))
+	assert.Equal(t, "ab", templateTruncate(2, "abcdef"))
+}
@@ -23,10 +23,11 @@ import (
 
 // Print colors
 var colors = map[check.State]*color.Color{
-	check.PASS: color.New(color.FgGreen),
-	check.FAIL: color.New(color.FgRed),
-	check.WARN: color.New(color.FgYellow),
-	check.INFO: color.New(color.FgBlue),
+	check.PASS:   color.New(color.FgGreen),
+	check.FAIL:   color.New(color.FgRed),
+	check.WARN:   color.New(color.FgYellow),
+	check.INFO:   color.New(color.FgBlue),
+	check.WAIVED: color.New(color.FgCyan),
 }
 
 var (
@@ -57,11 +58,16 @@ func (p Platform) String() string {
 	return fmt.Sprintf("Platform{ Name: %s Version: %s }", p.Name, p.Version)
 }
 
-func exitWithError(err error) {
-	fmt.Fprintf(os.Stderr, "\n%v\n", err)
-	// flush before exit non-zero
-	glog.Flush()
-	os.Exit(1)
+// getNodeName returns the name of the node kube-bench is running on.
+func getNodeName() string {
+	if name := viper.GetString("NODE_NAME"); name != "" {
+		return name
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		glog.V(2).Infof("received error looking up hostname: %s", err)
+	}
+	return host
 }
 
 func cleanIDs(list string) map[string]bool {
@@ -180,7 +186,7 @@ func decrementVersion(version string) string {
 }
 
 // getFiles finds which of the set of candidate files exist
-func getFiles(v *viper.Viper, fileType string) map[string]string {
+func getFiles(v *viper.Viper, fileType string) (map[string]string, error) {
 	filemap := make(map[string]string)
 	mainOpt := TypeMap[fileType][0]
 	defaultOpt := TypeMap[fileType][1]
@@ -192,7 +198,10 @@ func getFiles(v *viper.Viper, fileType string) map[string]string {
 		}
 
 		// See if any of the candidate files exist
-		file := findConfigFile(s.GetStringSlice(mainOpt))
+		file, err := findConfigFile(s.GetStringSlice(mainOpt))
+		if err != nil {
+			return nil, err
+		}
 		if file == "" {
 			if s.IsSet(defaultOpt) {
 				file = s.GetString(defaultOpt)
@@ -209,7 +218,7 @@ func getFiles(v *viper.Viper, fileType string) map[string]string {
 		filemap[component] = file
 	}
 
-	return filemap
+	return filemap, nil
 }
 
 // verifyBin checks that the binary specified is running
@@ -240,18 +249,18 @@ func verifyBin(bin string) bool {
 }
 
 // fundConfigFile looks through a list of possible config files and finds the first one that exists
-func findConfigFile(candidates []string) string {
+func findConfigFile(candidates []string) (string, error) {
 	for _, c := range candidates {
 		_, err := statFunc(c)
 		if err == nil {
-			return c
+			return c, nil
 		}
 		if !os.IsNotExist(err) && !strings.HasSuffix(err.Error(), "not a directory") {
-			exitWithError(fmt.Errorf("error looking for file %s: %v", c, err))
+			return "", fmt.Errorf("error looking for file %s: %w", c, err)
 		}
 	}
 
-	return ""
+	return "", nil
 }
 
 // findExecutable looks through a list of possible executable names and finds the first one that's running
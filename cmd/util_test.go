@@ -229,11 +229,13 @@ func TestFindConfigFile(t *testing.T) {
 		input       []string
 		statResults []error
 		exp         string
+		expErr      bool
 	}{
 		{input: []string{"myfile"}, statResults: []error{nil}, exp: "myfile"},
 		{input: []string{"thisfile", "thatfile"}, statResults: []error{os.ErrNotExist, nil}, exp: "thatfile"},
 		{input: []string{"thisfile", "thatfile"}, statResults: []error{os.ErrNotExist, os.ErrNotExist}, exp: ""},
 		{input: []string{"thisfile", "/etc/dummy/thatfile"}, statResults: []error{os.ErrNotExist, errors.New("stat /etc/dummy/thatfile: not a directory")}, exp: ""},
+		{input: []string{"thisfile", "thatfile"}, statResults: []error{os.ErrPermission, nil}, expErr: true},
 	}
 
 	statFunc = fakestat
@@ -241,7 +243,10 @@ func TestFindConfigFile(t *testing.T) {
 		t.Run(strconv.Itoa(id), func(t *testing.T) {
 			e = c.statResults
 			eIndex = 0
-			conf := findConfigFile(c.input)
+			conf, err := findConfigFile(c.input)
+			if (err != nil) != c.expErr {
+				t.Fatalf("Got error %v, expected an error: %v", err, c.expErr)
+			}
 			if conf != c.exp {
 				t.Fatalf("Got %s expected %s", conf, c.exp)
 			}
@@ -313,7 +318,10 @@ func TestGetConfigFiles(t *testing.T) {
 			e = c.statResults
 			eIndex = 0
 
-			m := getFiles(v, "config")
+			m, err := getFiles(v, "config")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 			if !reflect.DeepEqual(m, c.exp) {
 				t.Fatalf("Got %v\nExpected %v", m, c.exp)
 			}
@@ -388,7 +396,10 @@ func TestGetServiceFiles(t *testing.T) {
 			e = c.statResults
 			eIndex = 0
 
-			m := getFiles(v, "service")
+			m, err := getFiles(v, "service")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 			if !reflect.DeepEqual(m, c.exp) {
 				t.Fatalf("Got %v\nExpected %v", m, c.exp)
 			}
@@ -440,7 +451,10 @@ func TestGetDatadirFiles(t *testing.T) {
 			}
 			e = c.statResults
 			eIndex = 0
-			m := getFiles(v, "datadir")
+			m, err := getFiles(v, "datadir")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 			if !reflect.DeepEqual(m, c.exp) {
 				t.Fatalf("Got %v\nExpected %v", m, c.exp)
 			}
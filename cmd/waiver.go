@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	expiryDateLayout = "2006-01-02"
+	// waiverExpiryWarning is how long before expiry a waiver starts to be reported.
+	waiverExpiryWarning = 14 * 24 * time.Hour
+)
+
+type waiverList struct {
+	Waivers []check.Waiver `yaml:"waivers"`
+}
+
+// waiverScope describes where the current checks are running, and is matched
+// against check.WaiverScope.
+type waiverScope struct {
+	node      string
+	benchmark string
+	target    string
+}
+
+// waiverRunner wraps a check.Runner and applies waivers to the checks it runs.
+type waiverRunner struct {
+	runner  check.Runner
+	waivers []check.Waiver
+	scope   waiverScope
+	now     func() time.Time
+}
+
+func newWaiverRunner(runner check.Runner, waivers []check.Waiver, scope waiverScope) check.Runner {
+	return &waiverRunner{
+		runner:  runner,
+		waivers: waivers,
+		scope:   scope,
+		now:     time.Now,
+	}
+}
+
+// Run runs the check and, if it did not pass and an active waiver matches it, marks it as WAIVED.
+// The audit output and actual value are kept so the real state stays visible.
+func (r *waiverRunner) Run(c *check.Check) check.State {
+	state := r.runner.Run(c)
+	if state != check.FAIL && state != check.WARN {
+		return state
+	}
+
+	w, ok := r.match(c)
+	if !ok {
+		return state
+	}
+
+	expires, _ := parseExpiryDate(w.Expires)
+	now := r.now()
+	if !now.Before(expires) {
+		glog.Warningf("waiver for check %s (owner: %s, ticket: %s) expired on %s, reporting %s", c.ID, w.Owner, w.Ticket, w.Expires, state)
+		w.Expired = true
+		c.Waiver = &w
+		return state
+	}
+
+	if expires.Sub(now) < waiverExpiryWarning {
+		glog.Warningf("waiver for check %s (owner: %s, ticket: %s) expires on %s", c.ID, w.Owner, w.Ticket, w.Expires)
+	}
+
+	w.State = state
+	c.Waiver = &w
+	c.Reason = fmt.Sprintf("Waived (was %s): %s", state, w.Justification)
+	c.State = check.WAIVED
+	return c.State
+}
+
+func (r *waiverRunner) match(c *check.Check) (check.Waiver, bool) {
+	for _, w := range r.waivers {
+		if !matchID(w.ID, c.ID) {
+			continue
+		}
+		if !matchAny(w.Scope.Nodes, r.scope.node) ||
+			!matchAny(w.Scope.Benchmarks, r.scope.benchmark) ||
+			!matchAny(w.Scope.Targets, r.scope.target) {
+			continue
+		}
+		return w, true
+	}
+	return check.Waiver{}, false
+}
+
+// matchAny reports whether value matches one of the patterns; an empty list matches everything.
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if matchID(strings.TrimSpace(p), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadWaivers reads and validates a waivers YAML file.
+func loadWaivers(path string) ([]check.Waiver, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening waivers file %s: %v", path, err)
+	}
+
+	var f waiverList
+	if err := yaml.Unmarshal(in, &f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal waivers file %s: %v", path, err)
+	}
+
+	for i, w := range f.Waivers {
+		if isEmpty(w.ID) {
+			return nil, fmt.Errorf("waiver #%d in %s has no id", i+1, path)
+		}
+		if isEmpty(w.Justification) || isEmpty(w.Owner) {
+			return nil, fmt.Errorf("waiver for %s in %s must have a justification and an owner", w.ID, path)
+		}
+		if _, err := parseExpiryDate(w.Expires); err != nil {
+			return nil, fmt.Errorf("waiver for %s in %s has an invalid expiry date %q: %v", w.ID, path, w.Expires, err)
+		}
+	}
+
+	glog.V(1).Infof("Loaded %d waivers from %s", len(f.Waivers), path)
+	return f.Waivers, nil
+}
+
+// parseExpiryDate returns the instant a waiver or attestation stops applying,
+// which is the end of the expiry day in UTC.
+func parseExpiryDate(expires string) (time.Time, error) {
+	t, err := time.Parse(expiryDateLayout, strings.TrimSpace(expires))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(24 * time.Hour), nil
+}
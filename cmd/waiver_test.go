@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/stretchr/testify/assert"
+)
+
+// stateRunner is a check.Runner that sets a fixed state and actual value.
+type stateRunner struct {
+	state check.State
+}
+
+func (r stateRunner) Run(c *check.Check) check.State {
+	c.State = r.state
+	c.ActualValue = "permissions=777"
+	return c.State
+}
+
+func TestWaiverRunner(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	waivers := []check.Waiver{
+		{ID: "1.1.*", Justification: "managed by the cloud provider", Owner: "platform", Ticket: "SEC-1", Expires: "2024-12-31"},
+		{ID: "4.2.6", Scope: check.WaiverScope{Nodes: []string{"worker-*"}}, Justification: "legacy", Owner: "team", Expires: "2024-12-31"},
+		{ID: "4.2.7", Justification: "legacy", Owner: "team", Expires: "2024-05-31"},
+	}
+
+	cases := []struct {
+		name          string
+		id            string
+		node          string
+		state         check.State
+		expected      check.State
+		expectWaiver  bool
+		expectExpired bool
+	}{
+		{name: "failed check matching a glob is waived", id: "1.1.3", node: "master-1", state: check.FAIL, expected: check.WAIVED, expectWaiver: true},
+		{name: "warned check is waived", id: "1.1.3", node: "master-1", state: check.WARN, expected: check.WAIVED, expectWaiver: true},
+		{name: "passed check is not waived", id: "1.1.3", node: "master-1", state: check.PASS, expected: check.PASS},
+		{name: "check without waiver keeps its state", id: "1.2.1", node: "master-1", state: check.FAIL, expected: check.FAIL},
+		{name: "waiver scoped to other nodes does not apply", id: "4.2.6", node: "master-1", state: check.FAIL, expected: check.FAIL},
+		{name: "waiver scoped to this node applies", id: "4.2.6", node: "worker-3", state: check.FAIL, expected: check.WAIVED, expectWaiver: true},
+		{name: "expired waiver reverts to the real state", id: "4.2.7", node: "worker-3", state: check.FAIL, expected: check.FAIL, expectWaiver: true, expectExpired: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newWaiverRunner(stateRunner{state: c.state}, waivers, waiverScope{node: c.node, benchmark: "cis-1.9", target: "node"}).(*waiverRunner)
+			r.now = func() time.Time { return now }
+
+			chk := &check.Check{ID: c.id}
+			assert.Equal(t, c.expected, r.Run(chk))
+			assert.Equal(t, c.expected, chk.State)
+			assert.Equal(t, "permissions=777", chk.ActualValue)
+			if !c.expectWaiver {
+				assert.Nil(t, chk.Waiver)
+				return
+			}
+			assert.NotNil(t, chk.Waiver)
+			assert.Equal(t, c.expectExpired, chk.Waiver.Expired)
+			if !c.expectExpired {
+				assert.Equal(t, c.state, chk.Waiver.State)
+			}
+		})
+	}
+}
+
+func TestLoadWaivers(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	good := write("good.yaml", `
+waivers:
+- id: "1.2.*"
+  scope:
+    benchmarks: ["cis-1.9"]
+  justification: "accepted"
+  owner: "security"
+  ticket: "SEC-42"
+  expires: "2030-01-31"
+`)
+	waivers, err := loadWaivers(good)
+	assert.NoError(t, err)
+	assert.Equal(t, []check.Waiver{{
+		ID:            "1.2.*",
+		Scope:         check.WaiverScope{Benchmarks: []string{"cis-1.9"}},
+		Justification: "accepted",
+		Owner:         "security",
+		Ticket:        "SEC-42",
+		Expires:       "2030-01-31",
+	}}, waivers)
+
+	_, err = loadWaivers(write("noowner.yaml", "waivers:\n- id: 1.2.1\n  justification: x\n  expires: 2030-01-31\n"))
+	assert.Error(t, err)
+
+	_, err = loadWaivers(write("baddate.yaml", "waivers:\n- id: 1.2.1\n  justification: x\n  owner: y\n  expires: soon\n"))
+	assert.Error(t, err)
+
+	_, err = loadWaivers(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang/glog"
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+// watchSettleTime is how long a watched file must stay unchanged before the
+// checks that use it are run again, so that a file written in several steps
+// is only evaluated once.
+const watchSettleTime = time.Second
+
+// missingComponentFiles holds the files configured for the components that
+// didn't exist when the controls were loaded, so that watching notices when
+// they are created.
+var missingComponentFiles = make(map[string]bool)
+
+// fileWatcher reports changes to a set of files. It watches their
+// directories rather than the files themselves, so that files that are
+// replaced by renaming another file over them are still followed, and files
+// that don't exist yet are noticed when they are created.
+type fileWatcher struct {
+	w *fsnotify.Watcher
+
+	mu    sync.Mutex
+	files map[string]bool
+	dirs  map[string]bool
+}
+
+func newFileWatcher() (*fileWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start watching files: %v", err)
+	}
+	return &fileWatcher{w: w, files: make(map[string]bool), dirs: make(map[string]bool)}, nil
+}
+
+func (fw *fileWatcher) Close() error {
+	return fw.w.Close()
+}
+
+// setFiles replaces the files that are watched.
+func (fw *fileWatcher) setFiles(files []string) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	fw.files = make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		f = filepath.Clean(f)
+		fw.files[f] = true
+		dirs[filepath.Dir(f)] = true
+	}
+
+	for dir := range fw.dirs {
+		if !dirs[dir] {
+			if err := fw.w.Remove(dir); err != nil {
+				glog.V(2).Infof("failed to stop watching %s: %v", dir, err)
+			}
+			delete(fw.dirs, dir)
+		}
+	}
+	for dir := range dirs {
+		if fw.dirs[dir] {
+			continue
+		}
+		if err := fw.w.Add(dir); err != nil {
+			glog.Warningf("failed to watch %s: %v", dir, err)
+			continue
+		}
+		fw.dirs[dir] = true
+	}
+}
+
+func (fw *fileWatcher) watched(path string) bool {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.files[filepath.Clean(path)]
+}
+
+// run sends the watched files that changed to changes, sorted, until the
+// context is done. Changes made within settle of each other are
+// sent together.
+func (fw *fileWatcher) run(ctx context.Context, settle time.Duration, changes chan<- []string) {
+	pending := make(map[string]bool)
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fw.w.Events:
+			if !ok {
+				return
+			}
+			if !fw.watched(event.Name) {
+				continue
+			}
+			glog.V(2).Infof("Watched file changed: %s", event)
+			pending[filepath.Clean(event.Name)] = true
+			settled = time.After(settle)
+		case err, ok := <-fw.w.Errors:
+			if !ok {
+				return
+			}
+			glog.Warningf("error watching files: %v", err)
+		case <-settled:
+			settled = nil
+			var paths []string
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			select {
+			case changes <- paths:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// watchedFiles returns the files the results of a scan depend on: the
+// config, service, kubeconfig and CA files of the components, including those
+// that are missing, and the controls files of the targets.
+func watchedFiles(result *check.OverallControls, controlsFiles map[check.NodeType]string) []string {
+	var files []string
+	if result.Metadata != nil {
+		for _, c := range result.Metadata.Components {
+			for _, f := range []string{c.Config, c.Service, c.Kubeconfig, c.CAFile} {
+				if f != "" {
+					files = append(files, f)
+				}
+			}
+		}
+	}
+	for f := range missingComponentFiles {
+		files = append(files, f)
+	}
+	for _, f := range controlsFiles {
+		files = append(files, f)
+	}
+	return files
+}
+
+// usesFile returns whether the audits of a check refer to the file.
+func usesFile(c *check.Check, path string) bool {
+	for _, audit := range []string{c.Audit, c.AuditConfig, c.AuditEnv} {
+		if containsPath(audit, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPath returns whether s refers to path as a whole, rather than as
+// part of a longer path such as that of a file in a kubelet.conf.d directory
+// next to kubelet.conf.
+func containsPath(s, path string) bool {
+	if path == "" {
+		return false
+	}
+	for i := 0; i < len(s); {
+		j := strings.Index(s[i:], path)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(path)
+		if (start == 0 || !isPathByte(s[start-1])) && (end == len(s) || !isPathByte(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+func isPathByte(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("._-/", b) >= 0
+}
+
+// changedScanner runs again the checks of the previous results whose audits
+// refer to one of the changed files, and all the checks of the targets whose
+// controls file changed. The other results are kept.
+func changedScanner(previous *check.OverallControls, controlsFiles map[check.NodeType]string, changed []string) scanner {
+	return func(id string) (*check.OverallControls, error) {
+		var metadata check.Metadata
+		if previous.Metadata != nil {
+			metadata = *previous.Metadata
+		}
+		metadata.ScanID = id
+		metadata.StartTime = time.Now().UTC()
+		metadata.EndTime = time.Time{}
+		metadata.DurationSeconds = 0
+		// The components are recorded again as the controls are loaded.
+		metadata.Components = nil
+		scanMetadata = &metadata
+
+		var cc []*check.Controls
+		for _, controls := range previous.Controls {
+			updated, err := rerunChanged(controls, controlsFiles[controls.Type], changed)
+			if err != nil {
+				return nil, err
+			}
+			cc = append(cc, updated)
+		}
+
+		finishScanMetadata()
+		emitScanFinished(cc)
+		writeConfiguredOutputs(cc)
+		return newOverallControls(cc), nil
+	}
+}
+
+// rerunChanged reloads the controls of a target and runs again the checks
+// that use the changed files, returning the updated results.
+func rerunChanged(previous *check.Controls, controlsFile string, changed []string) (*check.Controls, error) {
+	if controlsFile == "" {
+		return previous, nil
+	}
+	controls, err := loadControls(previous.Type, controlsFile, detecetedKubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := NewRunFilter(filterOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error setting up run filter: %v", err)
+	}
+
+	for _, path := range changed {
+		if path == filepath.Clean(controlsFile) {
+			glog.V(1).Infof("Controls file %s changed, running all the %s checks", path, previous.Type)
+			if err := runControls(controls, filter); err != nil {
+				return nil, err
+			}
+			return controls, nil
+		}
+	}
+
+	affected := make(map[string]bool)
+	for _, g := range controls.Groups {
+		for _, c := range g.Checks {
+			for _, path := range changed {
+				if usesFile(c, path) {
+					affected[c.ID] = true
+				}
+			}
+		}
+	}
+	if len(affected) == 0 {
+		return previous, nil
+	}
+
+	glog.V(1).Infof("Running the %s checks that use %s", previous.Type, strings.Join(changed, ", "))
+	err = runControls(controls, func(group *check.Group, c *check.Check) bool {
+		return affected[c.ID] && filter(group, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous.UpdateResults(controls), nil
+}
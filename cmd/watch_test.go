@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveChanges(t *testing.T, changes <-chan []string) []string {
+	t.Helper()
+	select {
+	case paths := <-changes:
+		return paths
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change was reported")
+		return nil
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	dir := t.TempDir()
+	watched := filepath.Join(dir, "kubelet.conf")
+	other := filepath.Join(dir, "other.conf")
+	require.NoError(t, os.WriteFile(watched, []byte("a"), 0o600))
+
+	fw, err := newFileWatcher()
+	require.NoError(t, err)
+	defer fw.Close()
+	fw.setFiles([]string{watched})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []string)
+	go fw.run(ctx, 50*time.Millisecond, changes)
+
+	require.NoError(t, os.WriteFile(other, []byte("a"), 0o600))
+	require.NoError(t, os.WriteFile(watched, []byte("b"), 0o600))
+	require.NoError(t, os.Chmod(watched, 0o644))
+	assert.Equal(t, []string{watched}, receiveChanges(t, changes), "changes are reported once they settle, without unwatched files")
+
+	// Editors and the kubelet replace files by renaming a new file over them.
+	tmp := filepath.Join(dir, ".kubelet.conf.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("c"), 0o600))
+	require.NoError(t, os.Rename(tmp, watched))
+	assert.Equal(t, []string{watched}, receiveChanges(t, changes))
+
+	// Files that don't exist yet are noticed when they are created.
+	missing := filepath.Join(dir, "kubelet.conf.d", "10-flags.conf")
+	require.NoError(t, os.Mkdir(filepath.Dir(missing), 0o700))
+	fw.setFiles([]string{watched, missing})
+	require.NoError(t, os.WriteFile(missing, []byte("a"), 0o600))
+	assert.Equal(t, []string{missing}, receiveChanges(t, changes))
+
+	fw.setFiles(nil)
+	assert.Empty(t, fw.dirs)
+	assert.False(t, fw.watched(watched))
+}
+
+func TestWatchedFiles(t *testing.T) {
+	defer func() { missingComponentFiles = make(map[string]bool) }()
+	missingComponentFiles = map[string]bool{"/etc/kubernetes/kubelet.conf.d/10-flags.conf": true}
+	result := &check.OverallControls{Metadata: &check.Metadata{Components: []check.Component{
+		{Target: check.NODE, Name: "kubelet", Config: "/var/lib/kubelet/config.yaml", Kubeconfig: "/etc/kubernetes/kubelet.conf", DataDir: "/var/lib/kubelet"},
+		{Target: check.NODE, Name: "proxy", Service: "/etc/systemd/system/kube-proxy.service", CAFile: "/etc/kubernetes/pki/ca.crt"},
+	}}}
+	files := watchedFiles(result, map[check.NodeType]string{check.NODE: "cfg/cis-1.9/node.yaml"})
+	assert.ElementsMatch(t, []string{
+		"/var/lib/kubelet/config.yaml",
+		"/etc/kubernetes/kubelet.conf",
+		"/etc/systemd/system/kube-proxy.service",
+		"/etc/kubernetes/pki/ca.crt",
+		"/etc/kubernetes/kubelet.conf.d/10-flags.conf",
+		"cfg/cis-1.9/node.yaml",
+	}, files)
+}
+
+func TestUsesFile(t *testing.T) {
+	cases := []struct {
+		audit string
+		exp   bool
+	}{
+		{audit: "/bin/sh -c 'if test -e /etc/kubernetes/kubelet.conf; then stat -c %a /etc/kubernetes/kubelet.conf; fi'", exp: true},
+		{audit: "cat /etc/kubernetes/kubelet.conf", exp: true},
+		{audit: "cat \"/etc/kubernetes/kubelet.conf\"", exp: true},
+		{audit: "ls /etc/kubernetes/kubelet.conf.d/10-flags.conf", exp: false},
+		{audit: "cat /etc/kubernetes/kubelet.conf.bak /etc/kubernetes/kubelet.conf", exp: true},
+		{audit: "cat /host/etc/kubernetes/kubelet.conf", exp: false},
+		{audit: "", exp: false},
+	}
+	for _, tc := range cases {
+		c := &check.Check{Audit: tc.audit}
+		assert.Equal(t, tc.exp, usesFile(c, "/etc/kubernetes/kubelet.conf"), tc.audit)
+	}
+	assert.True(t, usesFile(&check.Check{AuditConfig: "cat /var/lib/kubelet/config.yaml"}, "/var/lib/kubelet/config.yaml"))
+}
+
+const watchTestControls = `---
+controls:
+version: "cis-1.9"
+id: 4
+text: "Worker Node Security Configuration"
+type: "node"
+groups:
+  - id: 4.1
+    text: "Worker Node Configuration Files"
+    checks:
+      - id: 4.1.1
+        text: "%s"
+        audit: "cat $kubeletconf"
+        tests:
+          test_items:
+            - flag: "secure"
+        scored: true
+      - id: 4.1.2
+        text: "Always passes"
+        audit: "echo ok"
+        tests:
+          test_items:
+            - flag: "ok"
+        scored: true
+`
+
+func TestChangedScanner(t *testing.T) {
+	defer viper.Reset()
+	restoreStat, restoreFilter := statFunc, filterOpts
+	defer func() {
+		statFunc, filterOpts = restoreStat, restoreFilter
+		controlsCollection, scanMetadata = nil, nil
+	}()
+	statFunc = os.Stat
+	filterOpts = FilterOpts{Scored: true, Unscored: true}
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "kubelet.conf")
+	controlsFile := filepath.Join(dir, "node.yaml")
+	require.NoError(t, os.WriteFile(conf, []byte("secure"), 0o600))
+	require.NoError(t, os.WriteFile(controlsFile, []byte(controlsText("Kubelet config is secure")), 0o600))
+	viper.Set("node", map[string]interface{}{
+		"components": []string{"kubelet"},
+		"kubelet":    map[string]interface{}{"confs": []string{conf}},
+	})
+
+	controlsCollection = nil
+	targetControlsFiles = make(map[check.NodeType]string)
+	require.NoError(t, runChecks(check.NODE, controlsFile, ""))
+	scanMetadata = &check.Metadata{ScanID: "first", Benchmark: "cis-1.9"}
+	previous := newOverallControls(controlsCollection)
+	require.Equal(t, check.Summary{Pass: 2}, previous.Totals)
+	unchanged := previous.Controls[0].Groups[0].Checks[1]
+
+	// Only the checks that use the changed file run again.
+	require.NoError(t, os.WriteFile(conf, []byte("open"), 0o600))
+	result, err := changedScanner(previous, targetControlsFiles, []string{conf})("second")
+	require.NoError(t, err)
+	assert.Equal(t, "second", result.Metadata.ScanID)
+	assert.Equal(t, "cis-1.9", result.Metadata.Benchmark)
+	assert.Equal(t, check.Summary{Pass: 1, Fail: 1}, result.Totals)
+	checks := result.Controls[0].Groups[0].Checks
+	assert.Equal(t, check.FAIL, checks[0].State)
+	assert.Same(t, unchanged, checks[1])
+	assert.Equal(t, check.PASS, previous.Controls[0].Groups[0].Checks[0].State, "the previous results are unchanged")
+
+	// Changes to files no check uses keep the results.
+	result2, err := changedScanner(result, targetControlsFiles, []string{filepath.Join(dir, "other.conf")})("third")
+	require.NoError(t, err)
+	assert.Same(t, result.Controls[0], result2.Controls[0])
+
+	// All the checks of a target run again when its controls file changes.
+	require.NoError(t, os.WriteFile(controlsFile, []byte(controlsText("Kubelet config is reviewed")), 0o600))
+	result3, err := changedScanner(result2, targetControlsFiles, []string{controlsFile})("fourth")
+	require.NoError(t, err)
+	checks = result3.Controls[0].Groups[0].Checks
+	assert.Equal(t, "Kubelet config is reviewed", checks[0].Text)
+	assert.NotSame(t, unchanged, checks[1])
+	assert.Equal(t, check.Summary{Pass: 1, Fail: 1}, result3.Totals)
+}
+
+func controlsText(text string) string {
+	return fmt.Sprintf(watchTestControls, text)
+}
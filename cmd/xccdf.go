@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/khulnasoft-lab/kube-bench/check"
+)
+
+func writeXCCDFOutput(controlsCollection []*check.Controls, destination string) error {
+	return writeXCCDF(controlsCollection, destination, false)
+}
+
+func writeARFOutput(controlsCollection []*check.Controls, destination string) error {
+	return writeXCCDF(controlsCollection, destination, true)
+}
+
+func writeXCCDF(controlsCollection []*check.Controls, destination string, arf bool) error {
+	out, err := check.XCCDF(controlsCollection, resultNode(), resultKubeBenchVersion(), time.Now(), arf)
+	if err != nil {
+		return fmt.Errorf("failed to output in XCCDF format: %v", err)
+	}
+	return printOutput(string(out), destination)
+}
@@ -0,0 +1,188 @@
+/*
+Package schematest validates JSON documents against the JSON Schemas of
+kube-bench in tests.
+*/
+package schematest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Validate validates a JSON document against a JSON Schema. It supports the
+// keywords the result schema uses: $ref to $defs, type, enum, properties,
+// required, additionalProperties, items and oneOf.
+func Validate(schema, data []byte) error {
+	var root map[string]interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return fmt.Errorf("failed to parse schema: %v", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse results: %v", err)
+	}
+
+	v := schemaValidator{root: root}
+	v.validate(root, doc, "$")
+	if len(v.errs) > 0 {
+		return fmt.Errorf("results do not match schema %v:\n%s", root["$id"], strings.Join(v.errs, "\n"))
+	}
+	return nil
+}
+
+type schemaValidator struct {
+	root map[string]interface{}
+	errs []string
+}
+
+func (v *schemaValidator) errorf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, doc interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, err := v.resolve(ref)
+		if err != nil {
+			v.errorf(path, "%v", err)
+			return
+		}
+		v.validate(def, doc, path)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		var matched int
+		var errs []string
+		for _, s := range oneOf {
+			sub := schemaValidator{root: v.root}
+			sub.validate(s.(map[string]interface{}), doc, path)
+			if len(sub.errs) == 0 {
+				matched++
+			} else {
+				errs = append(errs, sub.errs...)
+			}
+		}
+		if matched != 1 {
+			v.errorf(path, "matches %d of the oneOf schemas, want 1", matched)
+			if matched == 0 {
+				v.errs = append(v.errs, errs...)
+			}
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, doc) {
+		v.errorf(path, "is %s, want %v", jsonType(doc), t)
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		var found bool
+		for _, e := range enum {
+			if e == doc {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.errorf(path, "%v is not one of %v", doc, enum)
+		}
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, d, path)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range d {
+				v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, doc map[string]interface{}, path string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			if _, ok := doc[r.(string)]; !ok {
+				v.errorf(path, "missing required property %q", r)
+			}
+		}
+	}
+
+	var keys []string
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "." + k
+		if s, ok := properties[k].(map[string]interface{}); ok {
+			v.validate(s, doc[k], p)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.errorf(p, "is not allowed")
+			}
+		case map[string]interface{}:
+			v.validate(additional, doc[k], p)
+		}
+	}
+}
+
+func (v *schemaValidator) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/$defs/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	defs, _ := v.root["$defs"].(map[string]interface{})
+	def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unknown $ref %q", ref)
+	}
+	return def, nil
+}
+
+func matchesType(t interface{}, doc interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return isJSONType(t, doc)
+	case []interface{}:
+		for _, name := range t {
+			if isJSONType(name.(string), doc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isJSONType(name string, doc interface{}) bool {
+	if name == "integer" {
+		n, ok := doc.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return jsonType(doc) == name
+}
+
+func jsonType(doc interface{}) string {
+	switch doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}